@@ -0,0 +1,488 @@
+package reflectutils
+
+import (
+	"database/sql"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Converter converts value to the type it is registered for, see RegisterConverter.
+type Converter func(value interface{}) (interface{}, error)
+
+type converterKey struct {
+	from, to reflect.Type
+}
+
+var converters sync.Map
+
+// RegisterConverter registers fn to convert values of type from to type to, like a string to a decimal type
+// or an int to an enum type. Set calls it for values that can't be assigned before any other conversion,
+// and for strings that the built in parsing fails on.
+func RegisterConverter(from, to reflect.Type, fn Converter) {
+	converters.Store(converterKey{from: from, to: to}, fn)
+}
+
+// convert sets value to v with the registered converter, ok is false if there is none for their types.
+func convert(v reflect.Value, value interface{}) (ok bool, err error) {
+	fn, ok := converters.Load(converterKey{from: reflect.TypeOf(value), to: v.Type()})
+	if !ok {
+		return
+	}
+
+	var converted interface{}
+	converted, err = fn.(Converter)(value)
+	if err != nil {
+		return
+	}
+
+	cv := reflect.ValueOf(converted)
+	if !cv.IsValid() {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+	if !cv.Type().AssignableTo(v.Type()) {
+		err = fmt.Errorf("%w: converter returned %s for %s", ErrTypeMismatch, cv.Type(), v.Type())
+		return
+	}
+	v.Set(cv)
+	return
+}
+
+// assign sets value to v with the conversions Set applies to the last segment of a path,
+// nil sets zero value, string and []byte are unmarshaled by the type of v or parsed into primary types,
+// pointers are dereferenced, and values that can't be assigned are converted by convertValue.
+func assign(v reflect.Value, value interface{}, o *options) (err error) {
+	if value == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return
+	}
+
+	sv := v
+	for sv.Kind() == reflect.Ptr {
+		if sv.IsNil() {
+			sv.Set(reflect.New(sv.Type().Elem()))
+		}
+		sv = sv.Elem()
+	}
+
+	// interfaces keep strings as they are instead of parsing them, pointers are still dereferenced below
+	if t := reflect.TypeOf(value); sv.Kind() == reflect.Interface && t.Kind() != reflect.Ptr && t.AssignableTo(sv.Type()) {
+		sv.Set(reflect.ValueOf(value))
+		return
+	}
+
+	if s, ok := stringValue(value); ok {
+		err = setString(sv, s, o)
+		if err != nil {
+			if ok, cerr := convert(sv, value); ok {
+				return cerr
+			}
+		}
+		return
+	}
+
+	valv := reflect.ValueOf(value)
+	for valv.Kind() == reflect.Ptr {
+		if valv.IsNil() {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		valv = valv.Elem()
+	}
+
+	if !valv.Type().AssignableTo(sv.Type()) {
+		return convertValue(sv, valv, value, o)
+	}
+	sv.Set(valv)
+	return
+}
+
+// convertValue sets valv that can't be assigned to v with the registered converters, or numbers are converted
+// between kinds, json.Number is parsed like a string, and then sql.Scanner of v and fmt.Stringer of value are tried.
+func convertValue(v, valv reflect.Value, value interface{}, o *options) (err error) {
+	if ok, err := convert(v, valv.Interface()); ok {
+		return err
+	}
+
+	if ok, err := convertKind(v, valv); ok {
+		return err
+	}
+
+	if v.Type() == timeType && (isInt(valv.Kind()) || isUint(valv.Kind())) {
+		// integers are Unix seconds, like strings of digits in parseTime
+		n := reflect.New(reflect.TypeOf(int64(0))).Elem()
+		if _, err = convertKind(n, valv); err != nil {
+			return
+		}
+		v.Set(reflect.ValueOf(time.Unix(n.Int(), 0).UTC()))
+		return
+	}
+
+	if n, ok := valv.Interface().(json.Number); ok {
+		err = setString(v, n.String(), o)
+		if err != nil && isNumber(v.Kind()) {
+			// json.Number like 1e3 or 10.0 can be an integer
+			if f, ferr := n.Float64(); ferr == nil {
+				_, err = convertKind(v, reflect.ValueOf(f))
+			}
+		}
+		return
+	}
+
+	if reflect.PointerTo(v.Type()).Implements(scannerType) {
+		return unmarshal(v, func(p interface{}) error {
+			return p.(sql.Scanner).Scan(valv.Interface())
+		})
+	}
+
+	if s, ok := value.(fmt.Stringer); ok {
+		return setString(v, s.String(), o)
+	}
+
+	return fmt.Errorf("%w: %s can not be set to %s", ErrTypeMismatch, valv.Type(), v.Type())
+}
+
+// convertKind converts n to v if both are numbers, or both are strings or bools of different types,
+// ok is false otherwise. Numbers are checked not to overflow v, and floats with fractions
+// can't be converted to integers.
+func convertKind(v, n reflect.Value) (ok bool, err error) {
+	switch {
+	case v.Kind() == n.Kind() && (v.Kind() == reflect.String || v.Kind() == reflect.Bool):
+		v.Set(n.Convert(v.Type()))
+		return true, nil
+	case !isNumber(v.Kind()) || !isNumber(n.Kind()):
+		return
+	}
+
+	ok = true
+	overflow := fmt.Errorf("%w: %v overflows %s", ErrTypeMismatch, n, v.Type())
+	switch {
+	case isInt(v.Kind()):
+		var i int64
+		switch {
+		case isInt(n.Kind()):
+			i = n.Int()
+		case isUint(n.Kind()):
+			if n.Uint() > math.MaxInt64 {
+				return ok, overflow
+			}
+			i = int64(n.Uint())
+		default:
+			f := n.Float()
+			if f != math.Trunc(f) {
+				return ok, fmt.Errorf("%w: %v has a fraction for %s", ErrTypeMismatch, n, v.Type())
+			}
+			if f < math.MinInt64 || f >= math.MaxInt64 {
+				return ok, overflow
+			}
+			i = int64(f)
+		}
+		if v.OverflowInt(i) {
+			return ok, overflow
+		}
+		v.SetInt(i)
+
+	case isUint(v.Kind()):
+		var u uint64
+		switch {
+		case isInt(n.Kind()):
+			if n.Int() < 0 {
+				return ok, overflow
+			}
+			u = uint64(n.Int())
+		case isUint(n.Kind()):
+			u = n.Uint()
+		default:
+			f := n.Float()
+			if f != math.Trunc(f) {
+				return ok, fmt.Errorf("%w: %v has a fraction for %s", ErrTypeMismatch, n, v.Type())
+			}
+			if f < 0 || f >= math.MaxUint64 {
+				return ok, overflow
+			}
+			u = uint64(f)
+		}
+		if v.OverflowUint(u) {
+			return ok, overflow
+		}
+		v.SetUint(u)
+
+	default:
+		var f float64
+		switch {
+		case isInt(n.Kind()):
+			f = float64(n.Int())
+		case isUint(n.Kind()):
+			f = float64(n.Uint())
+		default:
+			f = n.Float()
+		}
+		if v.OverflowFloat(f) {
+			return ok, overflow
+		}
+		v.SetFloat(f)
+	}
+	return
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isNumber(k reflect.Kind) bool {
+	return isInt(k) || isUint(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+// stringValue returns the string of value if it is a string or a []byte.
+func stringValue(value interface{}) (s string, ok bool) {
+	switch inputv := value.(type) {
+	case string:
+		return inputv, true
+	case []byte:
+		return string(inputv), true
+	}
+	return
+}
+
+// setString parses s into v, time.Time and time.Duration are parsed first, then the unmarshalers
+// that v implements are used, and then primary types are parsed.
+func setString(v reflect.Value, s string, o *options) (err error) {
+	if ok, err := parseTime(v, s, o); ok {
+		return err
+	}
+	if ok, err := unmarshalString(v, s); ok {
+		return err
+	}
+	return setStringValue(v, s)
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// parseTime parses s into v if it is a time.Time or a time.Duration, ok is false for other types.
+// Times are parsed with the time layouts of o, or as Unix seconds, and durations like 1h30m, or as nanoseconds.
+func parseTime(v reflect.Value, s string, o *options) (ok bool, err error) {
+	switch v.Type() {
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			n, nerr := strconv.ParseInt(s, 10, 64)
+			if nerr != nil {
+				return true, fmt.Errorf("%w: %s", ErrTypeMismatch, err)
+			}
+			d = time.Duration(n)
+		}
+		v.SetInt(int64(d))
+		return true, nil
+
+	case timeType:
+		for _, layout := range o.layouts() {
+			if t, err := time.Parse(layout, s); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return true, nil
+			}
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			v.Set(reflect.ValueOf(time.Unix(n, 0).UTC()))
+			return true, nil
+		}
+		return true, fmt.Errorf("%w: %q doesn't match time layouts %s", ErrTypeMismatch, s, strings.Join(o.layouts(), ", "))
+	}
+	return
+}
+
+// formatTime formats time.Time with the first time layout of o, and time.Duration like 1h30m0s,
+// which parseTime parses back, ok is false for other types.
+func formatTime(v reflect.Value, o *options) (s string, ok bool) {
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return
+	}
+
+	switch v.Type() {
+	case durationType:
+		return time.Duration(v.Int()).String(), true
+	case timeType:
+		return v.Interface().(time.Time).Format(o.layouts()[0]), true
+	}
+	return
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	scannerType         = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// unmarshalString sets s to v with encoding.TextUnmarshaler, json.Unmarshaler or sql.Scanner
+// that the pointer of v implements, ok is false if it implements none of them.
+func unmarshalString(v reflect.Value, s string) (ok bool, err error) {
+	pt := reflect.PointerTo(v.Type())
+	if pt.Implements(textUnmarshalerType) {
+		return true, unmarshal(v, func(p interface{}) error {
+			return p.(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+		})
+	}
+
+	// []byte types like json.RawMessage keep the bytes as they are
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return
+	}
+
+	if pt.Implements(jsonUnmarshalerType) {
+		return true, unmarshal(v, func(p interface{}) error {
+			// s can be a JSON value like 12 or {"a":1}, or a string that has to be quoted
+			if json.Valid([]byte(s)) {
+				if err := p.(json.Unmarshaler).UnmarshalJSON([]byte(s)); err == nil {
+					return nil
+				}
+			}
+			quoted, _ := json.Marshal(s)
+			return p.(json.Unmarshaler).UnmarshalJSON(quoted)
+		})
+	}
+
+	if pt.Implements(scannerType) {
+		return true, unmarshal(v, func(p interface{}) error {
+			return p.(sql.Scanner).Scan(s)
+		})
+	}
+	return
+}
+
+// unmarshal calls fn with the pointer to a new value of the type of v, and sets it to v if fn succeeds,
+// so that v isn't changed by a failed unmarshaling.
+func unmarshal(v reflect.Value, fn func(p interface{}) error) (err error) {
+	nv := reflect.New(v.Type())
+	if err = fn(nv.Interface()); err != nil {
+		return fmt.Errorf("%w: %s", ErrTypeMismatch, err)
+	}
+	v.Set(nv.Elem())
+	return
+}
+
+// mapKey returns the key of the token in a map with key type keyType, the Key of the token if it has one,
+// otherwise Field converted by mapKey.
+func (t *dotToken) mapKey(keyType reflect.Type) (k reflect.Value, err error) {
+	if t.Key.IsValid() && t.Key.Type() == keyType {
+		return t.Key, nil
+	}
+	return mapKey(keyType, t.Field)
+}
+
+// mapKey converts key in a path to a value of map key type t, with encoding.TextUnmarshaler
+// if the key type implements it, otherwise with the same parsing as setStringValue.
+func mapKey(t reflect.Type, key string) (k reflect.Value, err error) {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		k = reflect.New(t)
+		err = k.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key))
+		return k.Elem(), err
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+	default:
+		err = fmt.Errorf("%w: map key type %s", ErrUnsupportedKind, t)
+		return
+	}
+
+	k = reflect.New(t).Elem()
+	err = setStringValue(k, key)
+	return
+}
+
+// formatValue formats primary types into string, the reverse of setStringValue.
+func formatValue(v reflect.Value) (s string, ok bool) {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		return string(v.Bytes()), true
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	}
+	return
+}
+
+func setStringValue(v reflect.Value, value string) (err error) {
+	s := value
+
+	// if type is []byte
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		v.SetBytes([]byte(s))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		n, err = strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return
+		}
+		if v.OverflowInt(n) {
+			err = fmt.Errorf("overflow int64 for %d", n)
+			return
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return
+		}
+		if v.OverflowUint(n) {
+			err = fmt.Errorf("overflow uint64 for %d", n)
+			return
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		n, err = strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return
+		}
+		if v.OverflowFloat(n) {
+			err = fmt.Errorf("overflow float64 for %f", n)
+			return
+		}
+		v.SetFloat(n)
+	case reflect.Bool:
+		var n bool
+		n, err = strconv.ParseBool(s)
+		if err != nil {
+			return
+		}
+		v.SetBool(n)
+	default:
+		err = fmt.Errorf("%w: value %+v can only been set to primary type but was %s", ErrTypeMismatch, value, v.Type())
+	}
+
+	return
+}
@@ -0,0 +1,319 @@
+package reflectutils_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+type Status int
+
+const (
+	StatusDraft Status = iota
+	StatusPublished
+)
+
+type Money struct {
+	Cents int64
+}
+
+type Invoice struct {
+	Status Status
+	Total  *Money
+	Note   string
+}
+
+func init() {
+	RegisterConverter(reflect.TypeOf(""), reflect.TypeOf(Money{}), func(value interface{}) (interface{}, error) {
+		var units, cents int64
+		if _, err := fmt.Sscanf(value.(string), "%d.%d", &units, &cents); err != nil {
+			return nil, err
+		}
+		return Money{Cents: units*100 + cents}, nil
+	})
+	RegisterConverter(reflect.TypeOf(""), reflect.TypeOf(Status(0)), func(value interface{}) (interface{}, error) {
+		switch strings.ToLower(value.(string)) {
+		case "draft":
+			return StatusDraft, nil
+		case "published":
+			return StatusPublished, nil
+		}
+		return nil, fmt.Errorf("unknown status %s", value)
+	})
+	RegisterConverter(reflect.TypeOf(false), reflect.TypeOf(Status(0)), func(value interface{}) (interface{}, error) {
+		if value.(bool) {
+			return StatusPublished, nil
+		}
+		return StatusDraft, nil
+	})
+	RegisterConverter(reflect.TypeOf(0), reflect.TypeOf(Status(0)), func(value interface{}) (interface{}, error) {
+		if s := Status(value.(int)); s == StatusDraft || s == StatusPublished {
+			return s, nil
+		}
+		return nil, fmt.Errorf("unknown status %d", value)
+	})
+	RegisterConverter(reflect.TypeOf(0), reflect.TypeOf(""), func(value interface{}) (interface{}, error) {
+		return 1, nil
+	})
+}
+
+func TestRegisterConverter(t *testing.T) {
+	var inv *Invoice
+
+	if err := Set(&inv, "Total", "12.34"); err != nil {
+		t.Fatal(err)
+	}
+	if inv.Total.Cents != 1234 {
+		t.Errorf("expected 1234 cents, but was %+v", inv.Total)
+	}
+
+	if err := Set(&inv, "Status", "Published"); err != nil || inv.Status != StatusPublished {
+		t.Errorf("expected published, but was %v, %v", err, inv.Status)
+	}
+	if err := Set(&inv, "Status", "0"); err != nil || inv.Status != StatusDraft {
+		t.Errorf("expected built in conversion first, but was %v, %v", err, inv.Status)
+	}
+	if err := Set(&inv, "Status", true); err != nil || inv.Status != StatusPublished {
+		t.Errorf("expected published from bool, but was %v, %v", err, inv.Status)
+	}
+	b := false
+	if err := Set(&inv, "Status", &b); err != nil || inv.Status != StatusDraft {
+		t.Errorf("expected draft from bool pointer, but was %v, %v", err, inv.Status)
+	}
+
+	if err := Set(&inv, "Status", 1); err != nil || inv.Status != StatusPublished {
+		t.Errorf("expected published from int, but was %v, %v", err, inv.Status)
+	}
+	if err := Set(&inv, "Status", 5); err == nil || err.Error() != "set Status: unknown status 5" {
+		t.Errorf("expected converter before number conversion, but was %v", err)
+	}
+	if err := Set(&inv, "Status", int8(0)); err != nil || inv.Status != StatusDraft {
+		t.Errorf("expected number conversion without converter, but was %v, %v", err, inv.Status)
+	}
+
+	if err := Set(&inv, "Status", "archived"); err == nil || err.Error() != "set Status: unknown status archived" {
+		t.Errorf("expected converter error, but was %v", err)
+	}
+	if err := Set(&inv, "Note", 12); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected type mismatch for converter result, but was %v", err)
+	}
+	if err := Set(&inv, "Total", 12.5); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected type mismatch without converter, but was %v", err)
+	}
+}
+
+type Tags []string
+
+func (t *Tags) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = strings.Split(s, ",")
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+type Server struct {
+	Addr   netip.Addr
+	Prefix *netip.Prefix
+	Name   sql.NullString
+	Port   sql.NullInt64
+	Tags   Tags
+	Meta   json.RawMessage
+}
+
+func TestSetUnmarshalers(t *testing.T) {
+	var s *Server
+
+	var setCases = []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{name: "Addr", value: "192.168.1.1", expected: "192.168.1.1"},
+		{name: "Prefix", value: []byte("10.0.0.0/8"), expected: "10.0.0.0/8"},
+		{name: "Name", value: "web", expected: "{web true}"},
+		{name: "Port", value: "8080", expected: "{8080 true}"},
+		{name: "Port", value: 443, expected: "{443 true}"},
+		{name: "Tags", value: "a,b", expected: "[a b]"},
+		{name: "Tags", value: `["c","d"]`, expected: "[c d]"},
+		{name: "Meta", value: "hello", expected: "hello"},
+	}
+
+	for _, c := range setCases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := Set(&s, c.name, c.value); err != nil {
+				t.Fatal(err)
+			}
+			v := MustGet(s, c.name)
+			if b, ok := v.(json.RawMessage); ok {
+				v = string(b)
+			}
+			if actual := fmt.Sprint(v); actual != c.expected {
+				t.Errorf("expected %s, but was %s", c.expected, actual)
+			}
+		})
+	}
+
+	if err := Set(&s, "Addr", "not an ip"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected type mismatch, but was %v", err)
+	}
+	if s.Addr.String() != "192.168.1.1" {
+		t.Errorf("expected addr unchanged, but was %s", s.Addr)
+	}
+	if err := Set(&s, "Port", "http"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected type mismatch, but was %v", err)
+	}
+}
+
+type Schedule struct {
+	Start    time.Time
+	End      *time.Time
+	Interval time.Duration
+	Timeout  *time.Duration
+}
+
+func TestSetTime(t *testing.T) {
+	var s *Schedule
+
+	var timeCases = []struct {
+		name     string
+		value    string
+		opts     []Option
+		expected string
+	}{
+		{name: "Start", value: "2024-05-01T10:30:00+08:00", expected: "2024-05-01T10:30:00+08:00"},
+		{name: "Start", value: "2024-05-01T10:30:00.5Z", expected: "2024-05-01T10:30:00.5Z"},
+		{name: "End", value: "2024-05-01", expected: "2024-05-01T00:00:00Z"},
+		{name: "End", value: "1714521600", expected: "2024-05-01T00:00:00Z"},
+		{name: "Start", value: "01/05/2024", opts: []Option{WithTimeLayouts("02/01/2006")}, expected: "01/05/2024"},
+		{name: "Interval", value: "1h30m", expected: "1h30m0s"},
+		{name: "Timeout", value: "1500ms", expected: "1.5s"},
+		{name: "Interval", value: "5", expected: "5ns"},
+	}
+
+	for _, c := range timeCases {
+		t.Run(c.name+" "+c.value, func(t *testing.T) {
+			if err := Set(&s, c.name, c.value, c.opts...); err != nil {
+				t.Fatal(err)
+			}
+			actual, err := GetAs[string](s, c.name, c.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != c.expected {
+				t.Errorf("expected %s, but was %s", c.expected, actual)
+			}
+			if err = Set(&s, c.name, actual, c.opts...); err != nil {
+				t.Errorf("expected formatted value set back, but was %v", err)
+			}
+		})
+	}
+
+	if s.Interval != 5 || *s.Timeout != 1500*time.Millisecond {
+		t.Errorf("unexpected durations %v, %v", s.Interval, *s.Timeout)
+	}
+	if d, err := GetAs[time.Duration](map[string]string{"ttl": "2m"}, "ttl"); err != nil || d != 2*time.Minute {
+		t.Errorf("expected 2m, but was %v, %v", d, err)
+	}
+
+	for _, n := range []interface{}{int64(1714521600), uint32(1714521600), 1714521600} {
+		if err := Set(&s, "End", n); err != nil || s.End.Format(time.RFC3339) != "2024-05-01T00:00:00Z" {
+			t.Errorf("expected %T set as Unix seconds, but was %v, %v", n, err, s.End)
+		}
+	}
+
+	if err := Set(&s, "Start", "May 1"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected type mismatch, but was %v", err)
+	}
+	if err := Set(&s, "Interval", "1 hour"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected type mismatch, but was %v", err)
+	}
+}
+
+type Reading struct {
+	Value   float64
+	Count   int32
+	Small   uint8
+	Label   string
+	Enabled bool
+}
+
+type Unit string
+
+type Sensor struct {
+	ID string
+}
+
+func (s Sensor) String() string {
+	return "sensor-" + s.ID
+}
+
+func TestSetNumbers(t *testing.T) {
+	var r *Reading
+
+	var numberCases = []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{name: "Value", value: 66, expected: "66"},
+		{name: "Value", value: uint16(7), expected: "7"},
+		{name: "Value", value: float32(1.5), expected: "1.5"},
+		{name: "Count", value: int64(12), expected: "12"},
+		{name: "Count", value: 3.0, expected: "3"},
+		{name: "Count", value: uint(9), expected: "9"},
+		{name: "Small", value: 255, expected: "255"},
+		{name: "Value", value: json.Number("2.25"), expected: "2.25"},
+		{name: "Count", value: json.Number("1e3"), expected: "1000"},
+		{name: "Small", value: json.Number("8"), expected: "8"},
+		{name: "Label", value: json.Number("8.5"), expected: "8.5"},
+		{name: "Label", value: Unit("kg"), expected: "kg"},
+		{name: "Label", value: Sensor{ID: "a1"}, expected: "sensor-a1"},
+		{name: "Label", value: &Sensor{ID: "b2"}, expected: "sensor-b2"},
+		{name: "Count", value: StatusPublished, expected: "1"},
+	}
+
+	for _, c := range numberCases {
+		t.Run(fmt.Sprintf("%s %T", c.name, c.value), func(t *testing.T) {
+			if err := Set(&r, c.name, c.value); err != nil {
+				t.Fatal(err)
+			}
+			if actual := fmt.Sprint(MustGet(r, c.name)); actual != c.expected {
+				t.Errorf("expected %s, but was %s", c.expected, actual)
+			}
+		})
+	}
+
+	var errorCases = []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "Small", value: 256},
+		{name: "Small", value: -1},
+		{name: "Count", value: int64(math.MaxInt32 + 1)},
+		{name: "Count", value: 1.5},
+		{name: "Count", value: json.Number("1.5")},
+		{name: "Value", value: true},
+		{name: "Enabled", value: 1},
+	}
+	for _, c := range errorCases {
+		t.Run(fmt.Sprintf("%s %v", c.name, c.value), func(t *testing.T) {
+			if err := Set(&r, c.name, c.value); !errors.Is(err, ErrTypeMismatch) {
+				t.Errorf("expected type mismatch, but was %v", err)
+			}
+		})
+	}
+	if r.Small != 8 || r.Count != 1 {
+		t.Errorf("expected values unchanged by errors, but was %+v", r)
+	}
+}
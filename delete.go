@@ -2,64 +2,152 @@ package reflectutils
 
 import (
 	"reflect"
-	"strconv"
-	"strings"
 )
 
-func Delete(i interface{}, name string) (err error) {
-	key := ""
-	if strings.HasSuffix(name, "]") {
-		lb := strings.LastIndex(name, "[")
-		key = name[lb+1 : len(name)-1]
-		name = name[0:lb]
+func Delete(i interface{}, name string, opts ...Option) (err error) {
+	var p *Path
+	p, err = Compile(name, opts...)
+	if err != nil {
+		return
+	}
+
+	return p.Delete(i)
+}
+
+func (p *Path) deletePath(i interface{}, tokens []*dotToken) (err error) {
+	var key *dotToken
+	if n := len(tokens); n > 0 && tokens[n-1].Bracketed {
+		key = tokens[n-1]
+		tokens = tokens[:n-1]
 	}
 
-	t := GetType(i, name)
+	t, err := p.valueType(i, tokens)
+	if err != nil {
+		return
+	}
 	if t == nil {
 		return NoSuchFieldError
 	}
 
-	for t.Kind() == reflect.Ptr {
-		t = t.Elem()
+	if key != nil && key.IsRange {
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return p.wrapError(key, t, ErrUnsupportedKind)
+		}
+		return p.deleteRange(i, tokens, key, t)
 	}
 
-	if t.Kind() == reflect.Slice {
-		var index int
-		index, err = strconv.Atoi(key)
-		if err != nil {
-			return
+	if key != nil && t.Kind() == reflect.Slice {
+		if !key.IsArray || key.IsAppendingArray {
+			return p.wrapError(key, t, NoSuchFieldError)
 		}
-		newSlice := reflect.MakeSlice(t, 0, 0)
-		v, err1 := Get(i, name)
-		if err1 != nil {
-			return err1
+
+		var vv reflect.Value
+		vv, _, err = p.get(reflect.ValueOf(i), tokens)
+		if err != nil || !vv.IsValid() {
+			return
 		}
-		vv := reflect.ValueOf(v)
-		for vv.Kind() == reflect.Ptr {
+		for vv.Kind() == reflect.Ptr || vv.Kind() == reflect.Interface {
 			vv = vv.Elem()
 		}
+
+		var index int
+		index, err = elemIndex(key, vv.Len())
+		if err != nil {
+			return p.wrapError(key, t, err)
+		}
+		if index >= vv.Len() {
+			return p.wrapError(key, t, ErrIndexOutOfRange)
+		}
+
+		newSlice := reflect.MakeSlice(t, 0, 0)
 		for j := 0; j < vv.Len(); j++ {
 			if j == index {
 				continue
 			}
 			newSlice = reflect.Append(newSlice, vv.Index(j))
 		}
-		return Set(i, name, newSlice.Interface())
+		return p.set(i, tokens, newSlice.Interface())
 	}
 
-	if t.Kind() == reflect.Map {
-		v := MustGet(i, name)
-		vv := reflect.ValueOf(v)
-		for vv.Kind() == reflect.Ptr {
+	if key != nil && t.Kind() == reflect.Array {
+		// arrays can't shrink, so the element is set to zero value
+		return p.set(i, append(tokens[:len(tokens):len(tokens)], key), nil)
+	}
+
+	if key != nil && t.Kind() == reflect.Map {
+		var vv reflect.Value
+		vv, _, err = p.get(reflect.ValueOf(i), tokens)
+		if err != nil || !vv.IsValid() {
+			return
+		}
+		for vv.Kind() == reflect.Ptr || vv.Kind() == reflect.Interface {
 			vv = vv.Elem()
 		}
-		vv.SetMapIndex(reflect.ValueOf(key), reflect.Value{})
+
+		var keyValue reflect.Value
+		keyValue, err = key.mapKey(t.Key())
+		if err != nil {
+			return p.wrapError(key, t, err)
+		}
+
+		vv.SetMapIndex(keyValue, reflect.Value{})
 		return
 	}
 
+	if key != nil {
+		return p.wrapError(key, t, NoSuchFieldError)
+	}
+
 	if t.Kind() == reflect.Struct {
-		return Set(i, name, nil)
+		return p.set(i, tokens, nil)
+	}
+
+	return p.set(i, tokens, reflect.Zero(t).Interface())
+}
+
+// keyTokens returns tokens with the last one bracketed if it is a map key or an index, so that
+// deletePath removes it like a bracketed segment instead of setting it to zero value.
+func (p *Path) keyTokens(i interface{}, tokens []*dotToken) []*dotToken {
+	n := len(tokens) - 1
+	if n < 0 || tokens[n].Bracketed {
+		return tokens
+	}
+
+	t, _ := p.valueType(i, tokens[:n])
+	if t == nil || (t.Kind() != reflect.Map && t.Kind() != reflect.Slice && t.Kind() != reflect.Array) {
+		return tokens
+	}
+
+	key := *tokens[n]
+	key.Bracketed = true
+	return append(tokens[:n:n], &key)
+}
+
+// deleteRange removes the elements in the range of key from the slice of tokens in one rebuild,
+// elements of an array are set to zero value.
+func (p *Path) deleteRange(i interface{}, tokens []*dotToken, key *dotToken, t reflect.Type) (err error) {
+	var vv reflect.Value
+	vv, _, err = p.get(reflect.ValueOf(i), tokens)
+	if err != nil || !vv.IsValid() {
+		return
+	}
+	for vv.Kind() == reflect.Ptr || vv.Kind() == reflect.Interface {
+		vv = vv.Elem()
+	}
+
+	start, end := elemRange(key, vv.Len())
+	if t.Kind() == reflect.Array {
+		for j := start; j < end; j++ {
+			err = p.set(i, append(tokens[:len(tokens):len(tokens)], indexToken(j)), nil)
+			if err != nil {
+				return
+			}
+		}
+		return
 	}
 
-	return Set(i, name, reflect.Zero(t).Interface())
+	newSlice := reflect.MakeSlice(t, 0, vv.Len()-(end-start))
+	newSlice = reflect.AppendSlice(newSlice, vv.Slice(0, start))
+	newSlice = reflect.AppendSlice(newSlice, vv.Slice(end, vv.Len()))
+	return p.set(i, tokens, newSlice.Interface())
 }
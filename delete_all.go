@@ -0,0 +1,53 @@
+package reflectutils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// DeleteAll deletes every element, map value or field that matches a path with wildcards, like Delete does,
+// `DeleteAll(obj, "..Password")` deletes the Password field at any depth, and count is the number of
+// values that have been deleted.
+func DeleteAll(i interface{}, name string, opts ...Option) (count int, err error) {
+	var p *Path
+	p, err = Compile(name, opts...)
+	if err != nil {
+		return
+	}
+
+	return p.DeleteAll(i)
+}
+
+// DeleteAll deletes every match of the compiled path, see DeleteAll.
+func (p *Path) DeleteAll(i interface{}) (count int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+		err = p.pathError("delete", err)
+	}()
+
+	var matches [][]*dotToken
+	err = p.walk(reflect.ValueOf(i), p.tokens, nil, func(walked, rest []*dotToken, v reflect.Value) error {
+		_, found, err := p.get(v, rest)
+		if err != nil || !found {
+			return err
+		}
+		matches = append(matches, append(walked, rest...))
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	// deleted in reverse, so that removing a slice element doesn't shift the indexes of the matches before it
+	for j := len(matches) - 1; j >= 0; j-- {
+		err = p.deletePath(i, p.keyTokens(i, matches[j]))
+		if err != nil {
+			return
+		}
+		count++
+	}
+	return
+}
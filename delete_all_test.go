@@ -0,0 +1,57 @@
+package reflectutils_test
+
+import (
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+type Credential struct {
+	User     string
+	Password string
+}
+
+type Payload struct {
+	Credential *Credential
+	Users      []Credential
+	Tokens     map[string]string
+	Password   string
+}
+
+func TestDeleteAll(t *testing.T) {
+	p := &Payload{
+		Credential: &Credential{User: "felix", Password: "1"},
+		Users:      []Credential{{User: "bin", Password: "2"}, {User: "anna", Password: "3"}},
+		Tokens:     map[string]string{"Password": "4", "session": "5"},
+		Password:   "6",
+	}
+
+	count, err := DeleteAll(p, "..Password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 5 {
+		t.Errorf("expected 5 deleted, but was %d", count)
+	}
+	if p.Password != "" || p.Credential.Password != "" || p.Users[0].Password != "" || p.Users[1].Password != "" {
+		t.Errorf("expected passwords deleted, but was %+v, %+v, %+v", p, p.Credential, p.Users)
+	}
+	if _, ok := p.Tokens["Password"]; ok || p.Tokens["session"] != "5" {
+		t.Errorf("expected map key removed, but was %+v", p.Tokens)
+	}
+	if p.Credential.User != "felix" || p.Users[1].User != "anna" {
+		t.Errorf("expected other fields kept, but was %+v, %+v", p.Credential, p.Users)
+	}
+
+	count, err = DeleteAll(p, "Users[*]")
+	if err != nil || count != 2 || len(p.Users) != 0 {
+		t.Errorf("expected every element removed, but was %d, %v, %+v", count, err, p.Users)
+	}
+
+	person := &Person{Name: "Felix"}
+	person.Projects = []*Project{{Name: "P1", Members: []*Person{person, {Name: "Bin"}}}}
+	count, err = DeleteAll(person, "..Members[0]")
+	if err != nil || count != 1 || len(person.Projects[0].Members) != 1 || person.Projects[0].Members[0].Name != "Bin" {
+		t.Errorf("expected cyclic member removed, but was %d, %v, %+v", count, err, person.Projects[0].Members)
+	}
+}
@@ -0,0 +1,93 @@
+package reflectutils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrNotAddressable is returned when a value can't be set or read, like unexported fields.
+	ErrNotAddressable = errors.New("not addressable")
+	// ErrUnsupportedKind is returned when a path goes into a value that has no fields, elements or keys.
+	ErrUnsupportedKind = errors.New("unsupported kind")
+	// ErrIndexOutOfRange is returned when an index can't address an element.
+	ErrIndexOutOfRange = errors.New("index out of range")
+	// ErrTypeMismatch is returned when a value can't be set to a field of a different type.
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrTestFailed is returned by ApplyPatch when the value of a test operation doesn't equal the value of its path.
+	ErrTestFailed = errors.New("test failed")
+
+	errWildcard      = errors.New("path that matches many values can only be used with GetAll, SetAll and DeleteAll")
+	errInvalidEscape = errors.New("~ must be followed by 0 or 1")
+	errMultiPointer  = errors.New("JSON Pointer can't have segments that match many values")
+)
+
+// PathError records the operation, the path and the segment of the path that failed.
+type PathError struct {
+	Path    string
+	Segment string
+	Offset  int
+	Op      string
+	Type    reflect.Type
+	Err     error
+}
+
+func (e *PathError) Error() string {
+	msg := fmt.Sprintf("%s %s: %s", e.Op, e.Path, e.Err)
+	if !isSegmentError(e.Err) {
+		return msg
+	}
+
+	if e.Segment != "" {
+		msg += fmt.Sprintf(" %q", e.Segment)
+	}
+	if e.Type != nil {
+		msg += " on " + e.Type.String()
+	}
+	return msg
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// isSegmentError reports whether the message of err needs the segment and type to make sense.
+func isSegmentError(err error) bool {
+	for _, target := range []error{NoSuchFieldError, ErrNotAddressable, ErrUnsupportedKind, ErrIndexOutOfRange, ErrTypeMismatch} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapError records the segment of token and the type it was resolved on into err,
+// errors that already know their segment are returned as they are.
+func (p *Path) wrapError(token *dotToken, t reflect.Type, err error) error {
+	if _, ok := err.(*PathError); ok || err == nil {
+		return err
+	}
+
+	return &PathError{
+		Path:    p.path,
+		Segment: token.Segment,
+		Offset:  token.Offset,
+		Type:    t,
+		Err:     err,
+	}
+}
+
+// pathError sets the operation of err, errors without a segment are wrapped with the whole path.
+func (p *Path) pathError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	pe, ok := err.(*PathError)
+	if !ok {
+		pe = &PathError{Path: p.path, Err: err}
+	}
+	pe.Op = op
+	return pe
+}
@@ -0,0 +1,110 @@
+package reflectutils_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+type Account struct {
+	Name     string
+	password string
+}
+
+func TestTypedErrors(t *testing.T) {
+	var p *Person
+	var a = &Account{password: "secret"}
+
+	var errorCases = []struct {
+		caseName string
+		run      func() error
+		expected error
+	}{
+		{
+			caseName: "set to non pointer",
+			run:      func() error { return Set(Person{}, "Name", "Felix") },
+			expected: ErrNotAddressable,
+		},
+		{
+			caseName: "set unexported field",
+			run:      func() error { return Set(a, "password", "123") },
+			expected: ErrNotAddressable,
+		},
+		{
+			caseName: "get unexported field",
+			run: func() error {
+				_, err := Get(a, "password")
+				return err
+			},
+			expected: ErrNotAddressable,
+		},
+		{
+			caseName: "set into string",
+			run:      func() error { return Set(&p, "Name.First", "Felix") },
+			expected: ErrUnsupportedKind,
+		},
+		{
+			caseName: "get into int",
+			run: func() error {
+				_, err := Get(&Person{}, "Gender.Code")
+				return err
+			},
+			expected: ErrUnsupportedKind,
+		},
+		{
+			caseName: "set map with array key",
+			run: func() error {
+				var m map[[2]int]string
+				return Set(&m, "1", "one")
+			},
+			expected: ErrUnsupportedKind,
+		},
+		{
+			caseName: "set negative index",
+			run:      func() error { return Set(&p, "Departments[-2].Name", "D") },
+			expected: ErrIndexOutOfRange,
+		},
+		{
+			caseName: "delete index past the end",
+			run: func() error {
+				return Delete(&Person{Departments: []*Department{{Name: "D"}}}, "Departments[10]")
+			},
+			expected: ErrIndexOutOfRange,
+		},
+		{
+			caseName: "delete pointer index past the end",
+			run: func() error {
+				return DeletePointer(&Team{Members: []*Member{{Name: "Felix"}}}, "/members/9")
+			},
+			expected: ErrIndexOutOfRange,
+		},
+		{
+			caseName: "set struct with int",
+			run:      func() error { return Set(&p, "Company", 12) },
+			expected: ErrTypeMismatch,
+		},
+		{
+			caseName: "set slice with string",
+			run:      func() error { return Set(&p, "Departments", "D") },
+			expected: ErrTypeMismatch,
+		},
+	}
+
+	for _, c := range errorCases {
+		t.Run(c.caseName, func(t *testing.T) {
+			err := c.run()
+			if !errors.Is(err, c.expected) {
+				t.Fatalf("expected %v, but was %v", c.expected, err)
+			}
+			if strings.Contains(err.Error(), "reflect:") {
+				t.Errorf("expected error without reflect panic message, but was %v", err)
+			}
+		})
+	}
+
+	if a.password != "secret" {
+		t.Errorf("unexported field should not be changed, but was %s", a.password)
+	}
+}
@@ -2,6 +2,7 @@ package reflectutils_test
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	. "github.com/sunfmin/reflectutils"
@@ -302,8 +303,10 @@ func ExampleSet_7notexists() {
 	err := Set(&p, "Whatever.Not.Exists", "911")
 
 	fmt.Println(err)
+	fmt.Println(errors.Is(err, NoSuchFieldError))
 	// Output:
-	// no such field
+	// set Whatever.Not.Exists: no such field "Whatever" on reflectutils_test.Person
+	// true
 }
 
 // Get Type of a deep nested object
@@ -340,6 +343,25 @@ func ExampleSet_8gettype() {
 	// <nil>
 }
 
+// Compile a path once and reuse it, so that the path string is not parsed again for every object
+func ExampleCompile() {
+	name := MustCompile("Departments[0].Name")
+
+	var people []*Person
+	for i := 1; i < 3; i++ {
+		var p *Person
+		name.Set(&p, fmt.Sprintf("Department %d", i))
+		people = append(people, p)
+	}
+
+	for _, p := range people {
+		fmt.Println(name.Get(p))
+	}
+	// Output:
+	// Department 1 <nil>
+	// Department 2 <nil>
+}
+
 // ForEach slice with any
 func ExampleForEach_9_0_normal() {
 	var arr = []*Language{
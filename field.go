@@ -0,0 +1,179 @@
+package reflectutils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// AmbiguousFieldError is returned in strict mode when more than one field at the same depth
+// of embedding matches a path segment.
+type AmbiguousFieldError struct {
+	Field      string
+	Candidates []string
+}
+
+func (e *AmbiguousFieldError) Error() string {
+	return fmt.Sprintf("ambiguous field %q matches %s", e.Field, strings.Join(e.Candidates, ", "))
+}
+
+type fieldKey struct {
+	t       reflect.Type
+	tagName string
+	strict  bool
+	name    string
+}
+
+// fieldCache remembers the index sequence of struct fields that have been resolved by name,
+// only found fields are stored so that random names in paths can't grow it without limit.
+var fieldCache sync.Map
+
+// fieldIndex returns the index sequence of the field of struct type t that matches name,
+// case-insensitively unless in strict mode.
+func fieldIndex(t reflect.Type, name string, o *options) (index []int, err error) {
+	key := fieldKey{t: t, tagName: o.tagName, strict: o.strict, name: name}
+	if !o.strict {
+		key.name = strings.ToLower(name)
+	}
+	if cached, found := fieldCache.Load(key); found {
+		return cached.([]int), nil
+	}
+
+	index, err = findField(t, name, o)
+	if err != nil {
+		return
+	}
+
+	fieldCache.Store(key, index)
+	return
+}
+
+func findField(t reflect.Type, name string, o *options) (index []int, err error) {
+	if o.tagName == "" && !o.strict {
+		sf, ok := t.FieldByNameFunc(func(fname string) bool {
+			return strings.EqualFold(fname, name)
+		})
+		if !ok {
+			return nil, NoSuchFieldError
+		}
+		return sf.Index, nil
+	}
+
+	// like FieldByNameFunc, the shallowest match wins and more than one match at that depth is ambiguous
+	var matches []reflect.StructField
+	for _, sf := range structFields(t, nil, map[reflect.Type]bool{}) {
+		fname := sf.Name
+		if o.tagName != "" {
+			var addressable bool
+			fname, addressable = tagFieldName(sf, o.tagName)
+			if !addressable {
+				continue
+			}
+		}
+
+		if o.strict && fname != name || !o.strict && !strings.EqualFold(fname, name) {
+			continue
+		}
+
+		if len(matches) > 0 {
+			if len(sf.Index) > len(matches[0].Index) {
+				continue
+			}
+			if len(sf.Index) < len(matches[0].Index) {
+				matches = matches[:0]
+			}
+		}
+		matches = append(matches, sf)
+	}
+
+	if len(matches) == 0 {
+		return nil, NoSuchFieldError
+	}
+
+	if len(matches) > 1 {
+		if !o.strict {
+			return nil, NoSuchFieldError
+		}
+
+		ambiguous := &AmbiguousFieldError{Field: name}
+		for _, sf := range matches {
+			ambiguous.Candidates = append(ambiguous.Candidates, fieldPathName(t, sf.Index))
+		}
+		return nil, ambiguous
+	}
+
+	return matches[0].Index, nil
+}
+
+// structFields returns the fields of struct t and the fields promoted from its embedded structs, unlike
+// reflect.VisibleFields it also returns the fields that are hidden by ambiguity.
+func structFields(t reflect.Type, index []int, visited map[reflect.Type]bool) (fields []reflect.StructField) {
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		sf.Index = append(append([]int{}, index...), i)
+		fields = append(fields, sf)
+
+		if !sf.Anonymous {
+			continue
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && !visited[ft] {
+			fields = append(fields, structFields(ft, sf.Index, visited)...)
+		}
+	}
+	return
+}
+
+// fieldPathName returns the names of the embedded fields and the field joined with dots, like `Base.ID`.
+func fieldPathName(t reflect.Type, index []int) string {
+	var names []string
+	for _, x := range index {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		sf := t.Field(x)
+		names = append(names, sf.Name)
+		t = sf.Type
+	}
+	return strings.Join(names, ".")
+}
+
+// tagFieldName returns the name in the struct tag, or the field name if the tag doesn't set one.
+func tagFieldName(sf reflect.StructField, tagName string) (name string, addressable bool) {
+	tag := sf.Tag.Get(tagName)
+	if tag == "-" {
+		return
+	}
+
+	name, _, _ = strings.Cut(tag, ",")
+	if name == "" {
+		name = sf.Name
+	}
+	return name, true
+}
+
+// fieldByIndex walks the index sequence from struct value v, nil embedded pointers are allocated
+// when alloc is true, otherwise an invalid value is returned for them.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
@@ -0,0 +1,100 @@
+package reflectutils
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fieldPhone struct {
+	Number string
+}
+
+type FieldBase struct {
+	ID      int
+	Created string
+}
+
+type fieldCompany struct {
+	*FieldBase
+	Name    string
+	Address string
+	Email   string
+	Website string
+	Phone   *fieldPhone
+}
+
+func TestFieldIndex(t *testing.T) {
+	typ := reflect.TypeOf(fieldCompany{})
+	for _, name := range []string{"name", "PHONE", "Created", "id", "FieldBase", "NotExists"} {
+		sf, expectedOK := typ.FieldByNameFunc(func(fname string) bool {
+			return strings.EqualFold(fname, name)
+		})
+
+		for j := 0; j < 2; j++ {
+			index, err := fieldIndex(typ, name, &options{})
+			ok := err == nil
+			if ok != expectedOK {
+				t.Errorf("%s: expected found %v, but was %v", name, expectedOK, ok)
+			}
+			if ok && !reflect.DeepEqual(index, sf.Index) {
+				t.Errorf("%s: expected index %v, but was %v", name, sf.Index, index)
+			}
+		}
+	}
+}
+
+func TestFieldIndexConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var c *fieldCompany
+			if err := Set(&c, "Phone.Number", "911"); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := Set(&c, "Created", "today"); err != nil {
+				t.Error(err)
+				return
+			}
+			if v := MustGet(c, "phone.number"); v != "911" {
+				t.Errorf("expected 911, but was %v", v)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFieldNilEmbeddedPointer(t *testing.T) {
+	c := &fieldCompany{}
+	v, err := Get(c, "Created")
+	if err != nil || v != nil {
+		t.Errorf("expected nil value of nil embedded struct, but was %v, %v", v, err)
+	}
+
+	if err = Set(c, "Created", "today"); err != nil {
+		t.Fatal(err)
+	}
+	if c.FieldBase == nil || c.Created != "today" {
+		t.Errorf("expected embedded struct allocated, but was %+v", c.FieldBase)
+	}
+}
+
+func BenchmarkFieldIndexCached(b *testing.B) {
+	typ := reflect.TypeOf(fieldCompany{})
+	for i := 0; i < b.N; i++ {
+		fieldIndex(typ, "Phone", &options{})
+	}
+}
+
+func BenchmarkFieldIndexUncached(b *testing.B) {
+	typ := reflect.TypeOf(fieldCompany{})
+	for i := 0; i < b.N; i++ {
+		typ.FieldByNameFunc(func(fname string) bool {
+			return strings.EqualFold(fname, "Phone")
+		})
+	}
+}
@@ -0,0 +1,312 @@
+package reflectutils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// filter is the predicate of a segment like [?(@.Id==2)], it matches the elements of a slice or an array,
+// or the values of a map, that it is true for.
+type filter interface {
+	match(p *Path, v reflect.Value) (bool, error)
+}
+
+type filterAnd struct {
+	left, right filter
+}
+
+func (f *filterAnd) match(p *Path, v reflect.Value) (ok bool, err error) {
+	ok, err = f.left.match(p, v)
+	if err != nil || !ok {
+		return
+	}
+	return f.right.match(p, v)
+}
+
+type filterOr struct {
+	left, right filter
+}
+
+func (f *filterOr) match(p *Path, v reflect.Value) (ok bool, err error) {
+	ok, err = f.left.match(p, v)
+	if err != nil || ok {
+		return
+	}
+	return f.right.match(p, v)
+}
+
+// filterCompare compares the value of a path relative to the element, `@` is the element itself,
+// with a literal that is parsed into the type of the value.
+type filterCompare struct {
+	tokens  []*dotToken
+	op      string
+	literal string
+	isNil   bool
+}
+
+func (f *filterCompare) match(p *Path, v reflect.Value) (ok bool, err error) {
+	v, found, err := p.get(v, f.tokens)
+	if err != nil || !found {
+		return
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			v = reflect.Value{}
+			break
+		}
+		v = v.Elem()
+	}
+
+	if f.isNil || !v.IsValid() {
+		switch f.op {
+		case "==":
+			return f.isNil == !v.IsValid(), nil
+		case "!=":
+			return f.isNil != !v.IsValid(), nil
+		}
+		return false, fmt.Errorf("%w: %s can only compare nil with == or !=", ErrTypeMismatch, f.op)
+	}
+
+	var lit reflect.Value
+	lit, err = mapKey(v.Type(), f.literal)
+	if err != nil {
+		return false, fmt.Errorf("%w: %q can not be compared with %s", ErrTypeMismatch, f.literal, v.Type())
+	}
+
+	var c int
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		c = compare(v.Int(), lit.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		c = compare(v.Uint(), lit.Uint())
+	case reflect.Float32, reflect.Float64:
+		c = compare(v.Float(), lit.Float())
+	case reflect.String:
+		c = strings.Compare(v.String(), lit.String())
+	default:
+		if f.op != "==" && f.op != "!=" || !v.Type().Comparable() {
+			return false, fmt.Errorf("%w: %s can not be compared with %s", ErrTypeMismatch, v.Type(), f.op)
+		}
+		if v.Interface() != lit.Interface() {
+			c = 1
+		}
+	}
+
+	switch f.op {
+	case "==":
+		ok = c == 0
+	case "!=":
+		ok = c != 0
+	case "<":
+		ok = c < 0
+	case "<=":
+		ok = c <= 0
+	case ">":
+		ok = c > 0
+	case ">=":
+		ok = c >= 0
+	}
+	return
+}
+
+func compare[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// filterParser parses the expression of a filter segment, offset is where expr starts in path.
+type filterParser struct {
+	path   string
+	expr   string
+	offset int
+	i      int
+}
+
+// parseFilter parses expr like `@.Id==2 && @.Name!="D"`, expr is at offset of path.
+func parseFilter(path, expr string, offset int) (f filter, err error) {
+	fp := &filterParser{path: path, expr: expr, offset: offset}
+	f, err = fp.parseOr()
+	if err != nil {
+		return
+	}
+	if fp.skipSpace(); fp.i < len(fp.expr) {
+		err = fp.error("unexpected " + fp.expr[fp.i:])
+	}
+	return
+}
+
+func (fp *filterParser) parseOr() (f filter, err error) {
+	f, err = fp.parseAnd()
+	for err == nil && fp.consume("||") {
+		var right filter
+		right, err = fp.parseAnd()
+		f = &filterOr{left: f, right: right}
+	}
+	return
+}
+
+func (fp *filterParser) parseAnd() (f filter, err error) {
+	f, err = fp.parseUnary()
+	for err == nil && fp.consume("&&") {
+		var right filter
+		right, err = fp.parseUnary()
+		f = &filterAnd{left: f, right: right}
+	}
+	return
+}
+
+func (fp *filterParser) parseUnary() (f filter, err error) {
+	if fp.consume("(") {
+		f, err = fp.parseOr()
+		if err == nil && !fp.consume(")") {
+			err = fp.error("missing )")
+		}
+		return
+	}
+
+	start := fp.i
+	left, err := fp.parseOperand()
+	if err != nil {
+		return
+	}
+
+	var op string
+	for _, o := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if fp.consume(o) {
+			op = o
+			break
+		}
+	}
+	if op == "" {
+		err = fp.error("missing comparison operator")
+		return
+	}
+
+	right, err := fp.parseOperand()
+	if err != nil {
+		return
+	}
+
+	if !left.isPath && right.isPath {
+		// the literal is on the left, like 2 < @.Id
+		left, right = right, left
+		op = map[string]string{"==": "==", "!=": "!=", "<": ">", ">": "<", "<=": ">=", ">=": "<="}[op]
+	}
+	if !left.isPath || right.isPath {
+		err = fp.errorAt(start, "a comparison needs a path starting with @ and a literal")
+		return
+	}
+
+	return &filterCompare{tokens: left.tokens, op: op, literal: right.literal, isNil: right.isNil}, nil
+}
+
+type filterOperand struct {
+	isPath  bool
+	tokens  []*dotToken
+	literal string
+	isNil   bool
+}
+
+// parseOperand parses a path starting with @, a quoted string, or a bare literal like 2, true or nil.
+func (fp *filterParser) parseOperand() (operand *filterOperand, err error) {
+	fp.skipSpace()
+	start := fp.i
+	if start >= len(fp.expr) {
+		err = fp.error("missing operand")
+		return
+	}
+
+	if q := fp.expr[start]; q == '"' || q == '\'' {
+		var lit string
+		lit, fp.i, err = unquoteKey(fp.expr, start)
+		if err != nil {
+			return nil, fp.errorAt(start, "missing closing quote")
+		}
+		return &filterOperand{literal: lit}, nil
+	}
+
+	depth := 0
+	var quote byte
+	for ; fp.i < len(fp.expr); fp.i++ {
+		c := fp.expr[fp.i]
+		if quote != 0 {
+			if c == '\\' {
+				fp.i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '"' || c == '\'' {
+			quote = c
+			continue
+		}
+		if c == '[' {
+			depth++
+			continue
+		}
+		if c == ']' {
+			depth--
+			continue
+		}
+		if depth == 0 && strings.IndexByte(" \t=!<>&|()", c) >= 0 {
+			break
+		}
+	}
+	word := fp.expr[start:fp.i]
+	if word == "" {
+		err = fp.error("missing operand")
+		return
+	}
+
+	if word[0] != '@' {
+		return &filterOperand{literal: word, isNil: word == "nil" || word == "null"}, nil
+	}
+
+	tokens, err := parsePath(word[1:])
+	if err != nil {
+		var pe *PathError
+		if errors.As(err, &pe) {
+			pe.Path = fp.path
+			pe.Offset += fp.offset + start + 1
+		}
+		return
+	}
+	for _, t := range tokens {
+		if t.isMulti() {
+			return nil, fp.errorAt(start, "path in filter can only match one value")
+		}
+		t.Offset += fp.offset + start + 1
+	}
+	return &filterOperand{isPath: true, tokens: tokens}, nil
+}
+
+func (fp *filterParser) skipSpace() {
+	for fp.i < len(fp.expr) && (fp.expr[fp.i] == ' ' || fp.expr[fp.i] == '\t') {
+		fp.i++
+	}
+}
+
+func (fp *filterParser) consume(s string) bool {
+	fp.skipSpace()
+	if strings.HasPrefix(fp.expr[fp.i:], s) {
+		fp.i += len(s)
+		return true
+	}
+	return false
+}
+
+func (fp *filterParser) error(msg string) error {
+	return fp.errorAt(fp.i, msg)
+}
+
+func (fp *filterParser) errorAt(i int, msg string) error {
+	return parseError(fp.path, fp.expr[i:], fp.offset+i, msg)
+}
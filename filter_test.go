@@ -0,0 +1,123 @@
+package reflectutils_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+func TestFilter(t *testing.T) {
+	p := &Person{
+		Departments: []*Department{{Id: 1, Name: "D1"}, {Id: 2, Name: "D2"}, nil, {Id: 3, Name: "D3"}},
+		Languages: map[string]Language{
+			"zh_CN": {Code: "zh_CN", Name: "China"},
+			"en_US": {Code: "en_US", Name: "United States"},
+		},
+		Phones: map[string]string{"home": "111", "work": "222"},
+	}
+
+	var filterCases = []struct {
+		name     string
+		expected string
+	}{
+		{
+			name:     "Departments[?(@.Id==2)].Name",
+			expected: `[D2]`,
+		},
+		{
+			name:     "Departments[?(@.Id >= 2 && @.Name != 'D3')].Name",
+			expected: `[D2]`,
+		},
+		{
+			name:     "Departments[?(@.Id<2 || @.Name==\"D3\")].Id",
+			expected: `[1 3]`,
+		},
+		{
+			name:     "Departments[?(2 < @.Id)].Name",
+			expected: `[D3]`,
+		},
+		{
+			name:     "Departments[?(@ == nil || (@.Id == 1))].Id",
+			expected: `[1]`,
+		},
+		{
+			name:     `Languages[?(@.Code=="en_US")].Name`,
+			expected: `[United States]`,
+		},
+		{
+			name:     "Phones[?(@ > '150')]",
+			expected: `[222]`,
+		},
+		{
+			name:     "Departments[?(@.Id==9)].Name",
+			expected: `[]`,
+		},
+	}
+
+	for _, c := range filterCases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := Get(p, c.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := fmt.Sprint(v)
+			if actual != c.expected {
+				t.Errorf("expected %s, but was %s", c.expected, actual)
+			}
+		})
+	}
+
+	matches, err := GetAll(p, "Departments[?(@.Id > 1)].Name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual := fmt.Sprintf("%+v", matches); actual != `[{Path:Departments[1].Name Value:D2} {Path:Departments[3].Name Value:D3}]` {
+		t.Errorf("expected matches with index paths, but was %s", actual)
+	}
+
+	if err = Set(p, "Departments[?(@.Id > 1)].Name", "Archived"); err != nil {
+		t.Fatal(err)
+	}
+	if p.Departments[0].Name != "D1" || p.Departments[1].Name != "Archived" || p.Departments[3].Name != "Archived" {
+		t.Errorf("expected filtered departments set, but was %+v, %+v, %+v", p.Departments[0], p.Departments[1], p.Departments[3])
+	}
+
+	if err = Set(p, `Languages[?(@.Code=="zh_CN")].Name`, "中国"); err != nil || p.Languages["zh_CN"].Name != "中国" {
+		t.Errorf("expected filtered map value set, but was %v, %+v", err, p.Languages)
+	}
+
+	if err = Delete(p, "Departments[?(@ == nil)]"); err != nil || len(p.Departments) != 3 {
+		t.Errorf("expected nil department deleted, but was %v, %+v", err, p.Departments)
+	}
+
+	if _, err = Get(p, "Departments[?(@.Id == 'abc')]"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected type mismatch, but was %v", err)
+	}
+	if _, err = Get(p, "Departments[?(@.Nmae == 'D1')]"); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field, but was %v", err)
+	}
+	if _, err = Get(p, "Company[?(@.Name == 'D1')]"); err != nil {
+		t.Errorf("expected no error for nil company, but was %v", err)
+	}
+	if _, _, err = Lookup(p, "Departments[?(@.Id == 1)]"); err == nil {
+		t.Errorf("expected Lookup with filter returns error")
+	}
+	if typ := GetType(p, "Departments[?(@.Id == 1)].Name"); typ == nil || typ.String() != "string" {
+		t.Errorf("expected string, but was %v", typ)
+	}
+
+	for _, name := range []string{
+		"Departments[?(@.Id == 1]",
+		"Departments[?(@.Id 1)]",
+		"Departments[?(@.Id == 1 &&)]",
+		"Departments[?(1 == 2)]",
+		"Departments[?(@.Id == 'a)]",
+		"Departments[?((@.Id == 1)]",
+	} {
+		if _, err = Compile(name); err == nil {
+			t.Errorf("expected parse error for %s", name)
+		}
+	}
+}
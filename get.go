@@ -4,13 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"strings"
 )
 
 // MustGet get value of a struct by path using reflect, return nil if anything in the path is nil
-func MustGet(i interface{}, name string) (value interface{}) {
+func MustGet(i interface{}, name string, opts ...Option) (value interface{}) {
 	var err error
-	value, err = Get(i, name)
+	value, err = Get(i, name, opts...)
 	if err != nil {
 		panic(fmt.Sprintf("%s: %s of %+v", err, name, i))
 	}
@@ -18,8 +17,10 @@ func MustGet(i interface{}, name string) (value interface{}) {
 }
 
 func IsNil(i interface{}) bool {
-	v := reflect.ValueOf(i)
+	return isNilValue(reflect.ValueOf(i))
+}
 
+func isNilValue(v reflect.Value) bool {
 	switch v.Kind() {
 	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan, reflect.UnsafePointer:
 		return v.IsNil()
@@ -29,107 +30,171 @@ func IsNil(i interface{}) bool {
 }
 
 // Get value of a struct by path using reflect.
-func Get(i interface{}, name string) (value interface{}, err error) {
-	// printv(i, name)
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.New(fmt.Sprint(r))
-		}
-	}()
-
-	if IsNil(i) {
+func Get(i interface{}, name string, opts ...Option) (value interface{}, err error) {
+	var p *Path
+	p, err = Compile(name, opts...)
+	if err != nil {
 		return
 	}
 
-	v := reflect.ValueOf(i)
+	return p.Get(i)
+}
 
-	if name == "" {
-		value = v.Interface()
+// Lookup value of a struct by path, found tells whether the path exists, so that a field that is
+// present but nil or zero can be told apart from a missing map key or an index past the end of a slice.
+func Lookup(i interface{}, name string, opts ...Option) (value interface{}, found bool, err error) {
+	var p *Path
+	p, err = Compile(name, opts...)
+	if err != nil {
 		return
 	}
 
-	var token *dotToken
-	token, err = nextDot(name)
+	return p.Lookup(i)
+}
+
+// Has reports whether the path exists in the object, see Lookup.
+func Has(i interface{}, name string, opts ...Option) bool {
+	p, err := Compile(name, opts...)
 	if err != nil {
+		return false
+	}
+
+	return p.Has(i)
+}
+
+// get returns the value of the path of tokens under v, found is false if a nil pointer, a missing map key
+// or an index past the end of a slice is in the path, value is invalid for nil values.
+func (p *Path) get(v reflect.Value, tokens []*dotToken) (value reflect.Value, found bool, err error) {
+	// printv(v, tokens)
+	if !v.IsValid() {
 		return
 	}
 
 	sv := v
+	for sv.Kind() == reflect.Ptr || sv.Kind() == reflect.Interface {
+		if sv.IsNil() {
+			found = len(tokens) == 0
+			return
+		}
+		if len(tokens) == 0 {
+			break
+		}
+		sv = sv.Elem()
+	}
+
+	if len(tokens) == 0 {
+		found = true
+		if !isNilValue(sv) {
+			value = sv
+		}
+		return
+	}
 
-	if sv.Kind() == reflect.Map {
-		// map must have string type
+	token := tokens[0]
+	defer func() {
+		if err != nil {
+			err = p.wrapError(token, sv.Type(), err)
+		}
+	}()
+
+	switch sv.Kind() {
+	case reflect.Map:
 		mv := sv
 
-		if mv.Type().Key() != reflect.TypeOf("") {
-			err = fmt.Errorf("map key %s must be string type", name)
+		if token.IsRange {
+			err = ErrUnsupportedKind
 			return
 		}
 
-		if mv.IsNil() {
+		var keyValue reflect.Value
+		keyValue, err = token.mapKey(mv.Type().Key())
+		if err != nil {
 			return
 		}
 
-		keyValue := reflect.ValueOf(token.Field)
-
-		elemType := mv.Type().Elem()
-		mapElem := reflect.New(elemType).Elem()
-		existElem := mv.MapIndex(keyValue)
-		if existElem.IsValid() {
-			mapElem.Set(existElem)
+		if mv.IsNil() {
+			return
 		}
 
-		value, err = Get(mapElem.Interface(), token.Left)
-		if err != nil {
+		mapElem := mv.MapIndex(keyValue)
+		if !mapElem.IsValid() {
+			// Get returns the zero value of a missing key, but it is not found
+			value, _, err = p.get(reflect.Zero(mv.Type().Elem()), tokens[1:])
 			return
 		}
-		return
-	}
 
-	if sv.Kind() == reflect.Slice {
+		return p.get(mapElem, tokens[1:])
+
+	case reflect.Slice, reflect.Array:
 		av := sv
 
-		if token.IsAppendingArray {
-			err = fmt.Errorf("array index is empty: %s", name)
+		if token.IsRange {
+			if len(tokens) > 1 {
+				err = errWildcard
+				return
+			}
+			value, found = subslice(av, token), true
 			return
 		}
 
-		if av.Len() <= token.ArrayIndex {
+		if !token.IsArray {
+			err = NoSuchFieldError
 			return
 		}
 
-		arrayElem := av.Index(token.ArrayIndex)
-		if !arrayElem.IsValid() {
+		if token.IsAppendingArray {
+			err = errors.New("array index is empty")
 			return
 		}
 
-		value, err = Get(arrayElem.Interface(), token.Left)
+		var index int
+		index, err = elemIndex(token, av.Len())
 		if err != nil {
 			return
 		}
 
-		return
-	}
-
-	if sv.Kind() != reflect.Struct {
-		for sv.Elem().Kind() == reflect.Ptr {
-			sv = sv.Elem()
+		if av.Len() <= index {
+			if av.Kind() == reflect.Array {
+				err = ErrIndexOutOfRange
+			}
+			return
 		}
 
-		sv = sv.Elem()
-	}
+		return p.get(av.Index(index), tokens[1:])
 
-	if sv.Kind() == reflect.Struct {
-		fv := sv.FieldByNameFunc(func(fname string) bool {
-			return strings.EqualFold(fname, token.Field)
-		})
+	case reflect.Struct:
+		var index []int
+		index, err = fieldIndex(sv.Type(), token.Field, &p.opts)
+		if err != nil {
+			return
+		}
 
+		fv := fieldByIndex(sv, index, false)
 		if !fv.IsValid() {
-			err = NoSuchFieldError
 			return
 		}
-		value, err = Get(fv.Interface(), token.Left)
-		return
+		if !fv.CanInterface() {
+			err = ErrNotAddressable
+			return
+		}
+
+		return p.get(fv, tokens[1:])
 	}
 
+	err = ErrUnsupportedKind
 	return
 }
+
+// subslice returns the elements in the range of token, elements of an array are copied to a new slice.
+func subslice(v reflect.Value, token *dotToken) reflect.Value {
+	start, end := elemRange(token, v.Len())
+	if v.Kind() == reflect.Slice {
+		return v.Slice3(start, end, end)
+	}
+
+	s := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), 0, end-start)
+	for i := start; i < end; i++ {
+		s = reflect.Append(s, v.Index(i))
+	}
+	return s
+}
@@ -0,0 +1,312 @@
+package reflectutils
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Match is a value found by GetAll, with the path to it that has no wildcards.
+type Match struct {
+	Path  string
+	Value interface{}
+}
+
+// GetAll gets every value that matches a path with wildcards, `*` matches every element of
+// a slice or an array, every key of a map, and every exported field of a struct,
+// like `Projects[*].Members[*].Name` or `Languages.*.Code`, and `..` finds the segment after it
+// at any depth, like `..Phone.Number`.
+func GetAll(i interface{}, name string, opts ...Option) (matches []Match, err error) {
+	var p *Path
+	p, err = Compile(name, opts...)
+	if err != nil {
+		return
+	}
+
+	return p.GetAll(i)
+}
+
+// GetAll gets every value that matches the compiled path, see GetAll.
+func (p *Path) GetAll(i interface{}) (matches []Match, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+		err = p.pathError("get", err)
+	}()
+
+	err = p.walk(reflect.ValueOf(i), p.tokens, nil, func(walked, rest []*dotToken, v reflect.Value) error {
+		v, found, err := p.get(v, rest)
+		if err != nil || !found {
+			return err
+		}
+
+		m := Match{Path: formatPath(append(walked, rest...))}
+		if v.IsValid() {
+			m.Value = v.Interface()
+		}
+		matches = append(matches, m)
+		return nil
+	})
+	return
+}
+
+// walk expands the wildcard tokens under v into the tokens of every child, and calls fn with the walked tokens,
+// the value they lead to, and the rest of the tokens that have no wildcards.
+func (p *Path) walk(v reflect.Value, tokens []*dotToken, walked []*dotToken, fn func(walked, rest []*dotToken, v reflect.Value) error) (err error) {
+	n := 0
+	for n < len(tokens) && !tokens[n].isMulti() {
+		n++
+	}
+	if n == len(tokens) {
+		return fn(walked[:len(walked):len(walked)], tokens, v)
+	}
+
+	var found bool
+	v, found, err = p.get(v, tokens[:n])
+	if err != nil || !found {
+		return
+	}
+	walked = append(walked[:len(walked):len(walked)], tokens[:n]...)
+
+	if tokens[n].IsDescendant {
+		return p.descend(v, tokens[n], tokens[n+1:], walked, fn, map[visit]bool{})
+	}
+
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return
+	}
+
+	var childTokens []*dotToken
+	var children []reflect.Value
+	childTokens, children, err = p.children(v)
+	if err == nil && tokens[n].Filter != nil && v.Kind() == reflect.Struct {
+		err = ErrUnsupportedKind
+	}
+	if err == nil && tokens[n].IsRange && v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		err = ErrUnsupportedKind
+	}
+	if err != nil {
+		return p.wrapError(tokens[n], v.Type(), err)
+	}
+
+	start, end := 0, len(children)
+	if tokens[n].IsRange {
+		start, end = elemRange(tokens[n], len(children))
+	}
+
+	for j, child := range children[:end] {
+		if j < start {
+			continue
+		}
+		if f := tokens[n].Filter; f != nil {
+			var ok bool
+			ok, err = f.match(p, child)
+			if err != nil {
+				return p.wrapError(tokens[n], v.Type(), err)
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		// errors of the child are reported at the wildcard
+		childTokens[j].Offset = tokens[n].Offset
+		err = p.walk(child, tokens[n+1:], append(walked[:len(walked):len(walked)], childTokens[j]), fn)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// visit is a pointer, map or slice that is being descended into, to stop at cycles.
+type visit struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// descend walks the rest of the tokens from every value under v at any depth that token matches,
+// values that token doesn't apply to are skipped.
+func (p *Path) descend(v reflect.Value, token *dotToken, rest, walked []*dotToken, fn func(walked, rest []*dotToken, v reflect.Value) error, visited map[visit]bool) (err error) {
+	for {
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if v.IsNil() {
+				return
+			}
+		}
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice:
+			key := visit{v.Type(), v.Pointer()}
+			if visited[key] {
+				return
+			}
+			visited[key] = true
+			defer delete(visited, key)
+		}
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			break
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return
+	}
+
+	matchTokens, matches := p.match(v, token)
+	for j, m := range matches {
+		matchTokens[j].Offset = token.Offset
+		err = p.walk(m, rest, append(walked[:len(walked):len(walked)], matchTokens[j]), fn)
+		if err != nil {
+			return
+		}
+	}
+
+	childTokens, children, cerr := p.children(v)
+	if cerr != nil {
+		return
+	}
+	for j, child := range children {
+		err = p.descend(child, token, rest, append(walked[:len(walked):len(walked)], childTokens[j]), fn, visited)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// match returns the children of v that token gets, without errors when it doesn't apply to v.
+func (p *Path) match(v reflect.Value, token *dotToken) (tokens []*dotToken, values []reflect.Value) {
+	if token.IsWildcard {
+		tokens, values, _ = p.children(v)
+		return
+	}
+
+	if token.Filter != nil {
+		if v.Kind() == reflect.Struct {
+			return
+		}
+		children, childValues, _ := p.children(v)
+		for j, child := range childValues {
+			if ok, err := token.Filter.match(p, child); err == nil && ok {
+				tokens = append(tokens, children[j])
+				values = append(values, child)
+			}
+		}
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Map:
+		if token.IsRange {
+			return
+		}
+		if k, err := token.mapKey(v.Type().Key()); err == nil {
+			if mv := v.MapIndex(k); mv.IsValid() {
+				kt := keyToken(token.Field)
+				kt.Key = k
+				return []*dotToken{kt}, []reflect.Value{mv}
+			}
+		}
+
+	case reflect.Slice, reflect.Array:
+		if token.IsRange {
+			start, end := elemRange(token, v.Len())
+			for i := start; i < end; i++ {
+				tokens = append(tokens, indexToken(i))
+				values = append(values, v.Index(i))
+			}
+			return
+		}
+		if !token.IsArray || token.IsAppendingArray {
+			return
+		}
+		if index, err := elemIndex(token, v.Len()); err == nil && index < v.Len() {
+			return []*dotToken{indexToken(index)}, []reflect.Value{v.Index(index)}
+		}
+
+	case reflect.Struct:
+		index, err := fieldIndex(v.Type(), token.Field, &p.opts)
+		if err != nil {
+			return
+		}
+		if fv := fieldByIndex(v, index, false); fv.IsValid() && fv.CanInterface() {
+			return []*dotToken{fieldToken(token.Field)}, []reflect.Value{fv}
+		}
+	}
+	return
+}
+
+// children returns the elements of a slice or an array, the values of a map sorted by key,
+// or the exported fields of a struct, with the tokens to get them.
+func (p *Path) children(v reflect.Value) (tokens []*dotToken, values []reflect.Value, err error) {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			tokens = append(tokens, indexToken(i))
+			values = append(values, v.Index(i))
+		}
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		names := make(map[reflect.Value]string, len(keys))
+		for _, k := range keys {
+			names[k] = mapKeyString(k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return names[keys[i]] < names[keys[j]]
+		})
+		for _, k := range keys {
+			kt := keyToken(names[k])
+			kt.Key = k
+			tokens = append(tokens, kt)
+			values = append(values, v.MapIndex(k))
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+
+			name := sf.Name
+			if p.opts.tagName != "" {
+				var addressable bool
+				name, addressable = tagFieldName(sf, p.opts.tagName)
+				if !addressable {
+					continue
+				}
+			}
+			tokens = append(tokens, fieldToken(name))
+			values = append(values, v.Field(i))
+		}
+
+	default:
+		err = ErrUnsupportedKind
+	}
+	return
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// mapKeyString formats a map key to the string that mapKey parses back.
+func mapKeyString(k reflect.Value) string {
+	if k.Type().Implements(textMarshalerType) {
+		if text, err := k.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	if s, ok := formatValue(k); ok {
+		return s
+	}
+	return fmt.Sprint(k.Interface())
+}
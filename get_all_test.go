@@ -0,0 +1,171 @@
+package reflectutils_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+func TestGetAll(t *testing.T) {
+	p := &Person{
+		Name: "Felix",
+		Projects: []*Project{
+			{
+				Name: "P1",
+				Members: []*Person{
+					{Name: "Juice"},
+					{Name: "Bin"},
+				},
+			},
+			nil,
+			{
+				Name: "P3",
+				Members: []*Person{
+					{Name: "Anna"},
+				},
+			},
+		},
+		Phones: map[string]string{
+			"home":        "111",
+			"example.com": "222",
+		},
+		Languages: map[string]Language{
+			"zh_CN": {Code: "zh_CN", Name: "China"},
+			"en_US": {Code: "en_US", Name: "United States"},
+		},
+	}
+
+	var getAllCases = []struct {
+		name     string
+		expected string
+	}{
+		{
+			name:     "Projects[*].Members[*].Name",
+			expected: `[{Path:Projects[0].Members[0].Name Value:Juice} {Path:Projects[0].Members[1].Name Value:Bin} {Path:Projects[2].Members[0].Name Value:Anna}]`,
+		},
+		{
+			name:     "Projects.*.Name",
+			expected: `[{Path:Projects[0].Name Value:P1} {Path:Projects[2].Name Value:P3}]`,
+		},
+		{
+			name:     "Languages.*.Code",
+			expected: `[{Path:Languages.en_US.Code Value:en_US} {Path:Languages.zh_CN.Code Value:zh_CN}]`,
+		},
+		{
+			name:     "Phones[*]",
+			expected: `[{Path:Phones["example.com"] Value:222} {Path:Phones.home Value:111}]`,
+		},
+		{
+			name:     "Projects[0].Members[1].*",
+			expected: `[{Path:Projects[0].Members[1].Name Value:Bin} {Path:Projects[0].Members[1].Score Value:0} {Path:Projects[0].Members[1].Gender Value:0} {Path:Projects[0].Members[1].Company Value:<nil>} {Path:Projects[0].Members[1].Departments Value:<nil>} {Path:Projects[0].Members[1].Projects Value:<nil>} {Path:Projects[0].Members[1].Phones Value:<nil>} {Path:Projects[0].Members[1].Languages Value:<nil>}]`,
+		},
+		{
+			name:     "Name",
+			expected: `[{Path:Name Value:Felix}]`,
+		},
+		{
+			name:     "Departments[*].Name",
+			expected: `[]`,
+		},
+	}
+
+	for _, c := range getAllCases {
+		t.Run(c.name, func(t *testing.T) {
+			matches, err := GetAll(p, c.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := fmt.Sprintf("%+v", matches)
+			if actual != c.expected {
+				t.Errorf("expected %s, but was %s", c.expected, actual)
+			}
+
+			for _, m := range matches {
+				if v := MustGet(p, m.Path); v != m.Value {
+					t.Errorf("get %s: expected %v, but was %v", m.Path, m.Value, v)
+				}
+			}
+		})
+	}
+
+	if _, err := GetAll(p, "Projects[*].Nmae"); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field, but was %v", err)
+	}
+	if _, err := GetAll(p, "Name.*"); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expected unsupported kind, but was %v", err)
+	}
+	if _, err := Get(p, "Projects[*].Name"); err == nil {
+		t.Errorf("expected Get with wildcard returns error")
+	}
+	if typ := GetType(p, "Projects[*].Members[*].Name"); typ == nil || typ.String() != "string" {
+		t.Errorf("expected string, but was %v", typ)
+	}
+}
+
+func TestGetAllDescendant(t *testing.T) {
+	p := &Person{
+		Name:    "Felix",
+		Company: &Company{Name: "The Plant", Phone: &Phone{Number: "111"}},
+	}
+	p.Projects = []*Project{
+		{
+			Name: "P1",
+			Members: []*Person{
+				p,
+				{Name: "Bin", Company: &Company{Phone: &Phone{Number: "222"}}},
+			},
+		},
+	}
+
+	var descendantCases = []struct {
+		name     string
+		expected string
+	}{
+		{
+			name:     "..Phone.Number",
+			expected: `[{Path:Company.Phone.Number Value:111} {Path:Projects[0].Members[1].Company.Phone.Number Value:222}]`,
+		},
+		{
+			name:     "Projects..Name",
+			expected: `[{Path:Projects[0].Name Value:P1} {Path:Projects[0].Members[0].Name Value:Felix} {Path:Projects[0].Members[0].Company.Name Value:The Plant} {Path:Projects[0].Members[1].Name Value:Bin} {Path:Projects[0].Members[1].Company.Name Value:}]`,
+		},
+		{
+			name:     "..Members[1].Name",
+			expected: `[{Path:Projects[0].Members[1].Name Value:Bin}]`,
+		},
+		{
+			name:     "..Phone.*",
+			expected: `[{Path:Company.Phone.Number Value:111} {Path:Projects[0].Members[1].Company.Phone.Number Value:222}]`,
+		},
+	}
+
+	for _, c := range descendantCases {
+		t.Run(c.name, func(t *testing.T) {
+			matches, err := GetAll(p, c.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual := fmt.Sprintf("%+v", matches)
+			if actual != c.expected {
+				t.Errorf("expected %s, but was %s", c.expected, actual)
+			}
+		})
+	}
+
+	if _, err := GetAll(p, "..Company.Nmae"); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field after a match, but was %v", err)
+	}
+	if _, err := Get(p, "..Name"); err == nil {
+		t.Errorf("expected Get with recursive descent returns error")
+	}
+	if typ := GetType(p, "..Name"); typ != nil {
+		t.Errorf("expected nil type for recursive descent, but was %v", typ)
+	}
+	for _, name := range []string{"Company..", "...Name", "Company.....Name"} {
+		if _, err := Compile(name); err == nil {
+			t.Errorf("expected parse error for %s", name)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package reflectutils
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GetAs gets value of a struct by path and converts it to T with the same rules Set uses,
+// so that a string field can be read as int, and primary types can be read as string.
+func GetAs[T any](i interface{}, name string, opts ...Option) (value T, err error) {
+	var v interface{}
+	v, err = Get(i, name, opts...)
+	if err != nil || v == nil {
+		return
+	}
+
+	o := newOptions(opts)
+	value, err = convertTo[T](v, &o)
+	if err != nil {
+		err = &PathError{Op: "get", Path: name, Err: err}
+	}
+	return
+}
+
+// MustGetAs is like GetAs but panics if the value can't be got or converted.
+func MustGetAs[T any](i interface{}, name string, opts ...Option) T {
+	value, err := GetAs[T](i, name, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("%s: %s of %+v", err, name, i))
+	}
+	return value
+}
+
+// GetOr is like GetAs but returns def if the value is nil or can't be converted to T.
+func GetOr[T any](i interface{}, name string, def T, opts ...Option) T {
+	v, err := Get(i, name, opts...)
+	if err != nil || v == nil {
+		return def
+	}
+
+	o := newOptions(opts)
+	value, err := convertTo[T](v, &o)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+func convertTo[T any](v interface{}, o *options) (value T, err error) {
+	if tv, ok := v.(T); ok {
+		return tv, nil
+	}
+
+	rv := reflect.ValueOf(&value).Elem()
+	if rv.Kind() == reflect.String {
+		if s, ok := formatTime(reflect.ValueOf(v), o); ok {
+			rv.SetString(s)
+			return
+		}
+		if s, ok := formatValue(reflect.ValueOf(v)); ok {
+			rv.SetString(s)
+			return
+		}
+	}
+
+	err = assign(rv, v, o)
+	return
+}
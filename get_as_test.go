@@ -0,0 +1,73 @@
+package reflectutils_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+type Setting struct {
+	Port    string
+	Ratio   float32
+	Enabled bool
+	Code    []byte
+	Company *Company
+}
+
+func TestGetAs(t *testing.T) {
+	s := &Setting{Port: "8080", Ratio: 0.5, Enabled: true, Code: []byte("42"), Company: &Company{Name: "The Plant"}}
+
+	if v, err := GetAs[int](s, "Port"); err != nil || v != 8080 {
+		t.Errorf("expected 8080, but was %v, %v", v, err)
+	}
+	if v, err := GetAs[string](s, "Ratio"); err != nil || v != "0.5" {
+		t.Errorf("expected 0.5, but was %v, %v", v, err)
+	}
+	if v, err := GetAs[string](s, "Enabled"); err != nil || v != "true" {
+		t.Errorf("expected true, but was %v, %v", v, err)
+	}
+	if v, err := GetAs[uint8](s, "Code"); err != nil || v != 42 {
+		t.Errorf("expected 42, but was %v, %v", v, err)
+	}
+	if v, err := GetAs[*Company](s, "Company"); err != nil || v != s.Company {
+		t.Errorf("expected the same company pointer, but was %v, %v", v, err)
+	}
+	if v, err := GetAs[Company](s, "Company"); err != nil || v.Name != "The Plant" {
+		t.Errorf("expected company value, but was %v, %v", v, err)
+	}
+	if v, err := GetAs[string](s, "Company.Phone.Number"); err != nil || v != "" {
+		t.Errorf("expected empty string for nil in path, but was %v, %v", v, err)
+	}
+
+	if _, err := GetAs[bool](s, "Port"); err == nil {
+		t.Errorf("expected parse error for 8080 to bool")
+	}
+	if _, err := GetAs[int](s, "Company"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected type mismatch, but was %v", err)
+	}
+	if _, err := GetAs[int](s, "NotExists"); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field, but was %v", err)
+	}
+
+	if v := MustGetAs[float64](s, "Port"); v != 8080 {
+		t.Errorf("expected 8080, but was %v", v)
+	}
+}
+
+func TestGetOr(t *testing.T) {
+	s := &Setting{Port: "abc"}
+
+	if v := GetOr(s, "Port", 80); v != 80 {
+		t.Errorf("expected default for bad value, but was %v", v)
+	}
+	if v := GetOr(s, "Company.Name", "none"); v != "none" {
+		t.Errorf("expected default for nil in path, but was %v", v)
+	}
+	if v := GetOr(s, "NotExists", true); v != true {
+		t.Errorf("expected default for missing field, but was %v", v)
+	}
+	if v := GetOr(s, "Port", "80"); v != "abc" {
+		t.Errorf("expected abc, but was %v", v)
+	}
+}
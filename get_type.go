@@ -2,51 +2,149 @@ package reflectutils
 
 import (
 	"reflect"
-	"strings"
 )
 
 // Get value of a struct by path using reflect.
-func GetType(i interface{}, name string) (t reflect.Type) {
-	var err error
+func GetType(i interface{}, name string, opts ...Option) (t reflect.Type) {
+	p, err := Compile(name, opts...)
+	if err != nil {
+		return nil
+	}
 
-	t = reflect.TypeOf(i)
+	return p.Type(i)
+}
 
-	if name == "" {
-		return
+func (p *Path) getType(t reflect.Type, tokens []*dotToken) (_ reflect.Type, err error) {
+	if t == nil || len(tokens) == 0 {
+		return t, nil
 	}
 
-	var token *dotToken
-	token, err = nextDot(name)
-	if err != nil {
-		return nil
+	token := tokens[0]
+	defer func() {
+		if err != nil {
+			err = p.wrapError(token, t, err)
+		}
+	}()
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
 	}
 
-	if t.Kind() == reflect.Map || t.Kind() == reflect.Slice {
-		t = GetType(reflect.Zero(t.Elem()).Interface(), token.Left)
-		return
+	if token.IsDescendant {
+		// values found at any depth don't have one type
+		return nil, errWildcard
 	}
 
-	if t.Kind() != reflect.Struct {
-		for t.Elem().Kind() == reflect.Ptr {
-			t = t.Elem()
+	switch t.Kind() {
+	case reflect.Map:
+		if token.IsWildcard || token.Filter != nil {
+			return p.getType(t.Elem(), tokens[1:])
+		}
+		if token.IsRange {
+			return nil, ErrUnsupportedKind
 		}
+		if _, err = token.mapKey(t.Key()); err != nil {
+			return nil, err
+		}
+		return p.getType(t.Elem(), tokens[1:])
 
-		t = t.Elem()
+	case reflect.Slice, reflect.Array:
+		if token.IsRange && len(tokens) == 1 {
+			if t.Kind() == reflect.Array {
+				return reflect.SliceOf(t.Elem()), nil
+			}
+			return t, nil
+		}
+		if token.IsWildcard || token.Filter != nil || token.IsRange {
+			return p.getType(t.Elem(), tokens[1:])
+		}
+		if !token.IsArray {
+			return nil, NoSuchFieldError
+		}
+		if t.Kind() == reflect.Array && !token.IsAppendingArray {
+			index, err := elemIndex(token, t.Len())
+			if err != nil || index >= t.Len() {
+				return nil, ErrIndexOutOfRange
+			}
+		}
+		return p.getType(t.Elem(), tokens[1:])
+
+	case reflect.Struct:
+		var index []int
+		index, err = fieldIndex(t, token.Field, &p.opts)
+		if err != nil {
+			return nil, err
+		}
+
+		return p.getType(t.FieldByIndex(index).Type, tokens[1:])
+
+	case reflect.Interface:
+		// the types under an interface{} are only known by its value
+		if t.NumMethod() == 0 {
+			return t, nil
+		}
 	}
 
-	if t.Kind() == reflect.Struct {
+	return nil, ErrUnsupportedKind
+}
 
-		sf, ok := t.FieldByNameFunc(func(name string) bool {
-			return strings.EqualFold(name, token.Field)
-		})
+// typeOf is like getType, but the types under an interface{} are resolved by its value in i,
+// paths under an interface{} that has no value have no type.
+func (p *Path) typeOf(i interface{}, tokens []*dotToken) (t reflect.Type, err error) {
+	base, start := reflect.TypeOf(i), 0
+	for j := 0; j < len(tokens) && !tokens[j].isMulti(); j++ {
+		t, err = p.getType(base, tokens[start:j])
+		if err != nil || t == nil {
+			return
+		}
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Interface || t.NumMethod() != 0 {
+			continue
+		}
 
-		if !ok {
-			return nil
+		var v reflect.Value
+		var found bool
+		v, found, err = p.get(reflect.ValueOf(i), tokens[:j])
+		if err != nil || !found {
+			return nil, err
+		}
+		for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+			v = v.Elem()
 		}
+		if !v.IsValid() || v.Kind() == reflect.Interface {
+			return nil, nil
+		}
+		base, start = v.Type(), j
+	}
+	return p.getType(base, tokens[start:])
+}
 
-		t = GetType(reflect.Zero(sf.Type).Interface(), token.Left)
+// valueType is like getType, but the type in an interface is resolved by the value of the path in i,
+// and pointers are dereferenced.
+func (p *Path) valueType(i interface{}, tokens []*dotToken) (t reflect.Type, err error) {
+	t, err = p.getType(reflect.TypeOf(i), tokens)
+	if err != nil || t == nil {
 		return
 	}
 
+	if t.Kind() == reflect.Interface {
+		var v reflect.Value
+		v, _, err = p.get(reflect.ValueOf(i), tokens)
+		if err != nil {
+			return
+		}
+		for v.Kind() == reflect.Interface && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.IsValid() {
+			t = v.Type()
+		}
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
 	return
 }
@@ -1,7 +1,10 @@
 package reflectutils_test
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strconv"
 	"testing"
 
 	. "github.com/sunfmin/reflectutils"
@@ -204,9 +207,10 @@ func TestDelete(t *testing.T) {
 		},
 
 		{
-			caseName: "Delete slice element in object overflow",
-			obj:      p,
-			name:     "Departments[100]",
+			caseName:    "Delete slice element in object overflow",
+			obj:         p,
+			name:        "Departments[100]",
+			expectedErr: `delete Departments[100]: index out of range "[100]" on []*reflectutils_test.Department`,
 			result: func(obj interface{}) (v interface{}) {
 				return obj.(*Person).Departments[1]
 			},
@@ -217,7 +221,7 @@ func TestDelete(t *testing.T) {
 			caseName:    "Delete struct with error",
 			obj:         p,
 			name:        "Comp21",
-			expectedErr: "no such field",
+			expectedErr: `delete Comp21: no such field "Comp21" on reflectutils_test.Person`,
 		},
 
 		{
@@ -234,7 +238,7 @@ func TestDelete(t *testing.T) {
 			caseName:    "Delete with wrong index",
 			obj:         p,
 			name:        "Departments[abc].Name",
-			expectedErr: "no such field",
+			expectedErr: `delete Departments[abc].Name: no such field "[abc]" on []*reflectutils_test.Department`,
 		},
 
 		{
@@ -289,3 +293,432 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+type Point struct {
+	X int
+	Y int
+}
+
+type Shape struct {
+	ID     [4]byte
+	Coords [3]Point
+	Refs   *[2]*Point
+}
+
+func TestArrays(t *testing.T) {
+	var s *Shape
+
+	if err := Set(&s, "Coords[1].X", 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(&s, "ID[3]", "7"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(&s, "Refs[1].Y", "5"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Coords[1].X != 10 || s.ID[3] != 7 || s.Refs[1].Y != 5 || s.Refs[0] != nil {
+		t.Errorf("set array element failed, %+v", s)
+	}
+
+	if v := MustGet(s, "Coords[1].X"); v != 10 {
+		t.Errorf("expected 10, but was %v", v)
+	}
+	if typ := GetType(s, "Coords[2]"); typ == nil || typ.String() != "reflectutils_test.Point" {
+		t.Errorf("expected element type Point, but was %v", typ)
+	}
+	if typ := GetType(s, "Refs[0].X"); typ == nil || typ.String() != "int" {
+		t.Errorf("expected int, but was %v", typ)
+	}
+
+	for _, name := range []string{"Coords[3].X", "ID[-5]"} {
+		if err := Set(&s, name, 1); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("set %s: expected index out of range, but was %v", name, err)
+		}
+		if _, err := Get(s, name); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("get %s: expected index out of range, but was %v", name, err)
+		}
+	}
+	if err := Set(&s, "Coords[].X", 1); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expected unsupported append to array, but was %v", err)
+	}
+
+	if err := Delete(s, "Coords[1]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(s, "Refs[1]"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Coords[1] != (Point{}) || s.Refs[1] != nil {
+		t.Errorf("expected array element to be zero, but was %+v", s)
+	}
+	if err := Delete(s, "ID[4]"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("expected index out of range, but was %v", err)
+	}
+}
+
+type Level int
+
+type Region string
+
+type UUID [16]byte
+
+func (u *UUID) UnmarshalText(text []byte) error {
+	_, err := hex.Decode(u[:], text)
+	return err
+}
+
+type Registry struct {
+	ByID      map[int]*Department
+	ByLevel   map[Level]string
+	ByRegion  map[Region]Language
+	ByUUID    map[UUID]string
+	Enabled   map[bool]string
+	Locations map[Point]string
+}
+
+func TestNonStringMapKeys(t *testing.T) {
+	var r *Registry
+	id := "000102030405060708090a0b0c0d0e0f"
+
+	var setCases = []struct {
+		name  string
+		value string
+	}{
+		{name: "ByID.12.Name", value: "D12"},
+		{name: "ByID[3].Name", value: "D3"},
+		{name: "ByLevel.2", value: "Two"},
+		{name: "ByRegion.eu.Code", value: "EU"},
+		{name: "ByUUID." + id, value: "first"},
+		{name: "Enabled.true", value: "on"},
+	}
+	for _, c := range setCases {
+		if err := Set(&r, c.name, c.value); err != nil {
+			t.Fatal(err)
+		}
+		if v := MustGet(r, c.name); v != c.value {
+			t.Errorf("get %s: expected %s, but was %v", c.name, c.value, v)
+		}
+	}
+
+	var uuid UUID
+	uuid.UnmarshalText([]byte(id))
+	if r.ByID[12].Name != "D12" || r.ByID[3].Name != "D3" || r.ByLevel[2] != "Two" ||
+		r.ByRegion["eu"].Code != "EU" || r.ByUUID[uuid] != "first" || r.Enabled[true] != "on" {
+		t.Errorf("set non string map keys failed, %+v", r)
+	}
+
+	if typ := GetType(r, "ByID.12.Name"); typ == nil || typ.String() != "string" {
+		t.Errorf("expected string, but was %v", typ)
+	}
+	if typ := GetType(r, "ByID.abc.Name"); typ != nil {
+		t.Errorf("expected nil type for key abc, but was %v", typ)
+	}
+
+	if err := Set(&r, "ByID.abc.Name", "D"); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("expected syntax error, but was %v", err)
+	}
+	if err := Set(&r, "ByUUID.xyz", "D"); err == nil {
+		t.Errorf("expected error for invalid uuid")
+	}
+	if err := Set(&r, "Locations.abc", "D"); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expected unsupported kind, but was %v", err)
+	}
+
+	if err := Delete(r, "ByID[12]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(r, "ByUUID["+id+"]"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := r.ByID[12]; ok || len(r.ByID) != 1 || len(r.ByUUID) != 0 {
+		t.Errorf("delete non string map keys failed, %+v", r)
+	}
+}
+
+func TestQuotedMapKeys(t *testing.T) {
+	var p *Person
+
+	var quotedCases = []struct {
+		name string
+		key  string
+	}{
+		{name: `Phones["example.com"]`, key: "example.com"},
+		{name: `Phones['v1.2']`, key: "v1.2"},
+		{name: `Phones['a[b]']`, key: "a[b]"},
+		{name: `Phones["say \"hi\""]`, key: `say "hi"`},
+		{name: `Phones['it\'s']`, key: "it's"},
+		{name: `Phones["back\\slash"]`, key: `back\slash`},
+		{name: `Phones["0"]`, key: "0"},
+	}
+
+	for _, c := range quotedCases {
+		if err := Set(&p, c.name, c.key); err != nil {
+			t.Fatal(err)
+		}
+		if p.Phones[c.key] != c.key {
+			t.Errorf("set %s: expected key %q, but was %+v", c.name, c.key, p.Phones)
+		}
+		if v := MustGet(p, c.name); v != c.key {
+			t.Errorf("get %s: expected %q, but was %v", c.name, c.key, v)
+		}
+		if typ := GetType(p, c.name); typ == nil || typ.String() != "string" {
+			t.Errorf("type %s: expected string, but was %v", c.name, typ)
+		}
+	}
+	if len(p.Phones) != len(quotedCases) {
+		t.Errorf("expected no nested keys, but was %+v", p.Phones)
+	}
+
+	if err := Set(&p, `Languages["en.US"].Code`, "en_US"); err != nil || p.Languages["en.US"].Code != "en_US" {
+		t.Errorf("set quoted key with field after failed, %v, %+v", err, p.Languages)
+	}
+
+	if err := Delete(p, `Phones["a[b]"]`); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.Phones["a[b]"]; ok {
+		t.Errorf("expected a[b] deleted, but was %+v", p.Phones)
+	}
+
+	for _, name := range []string{`Phones["example.com]`, `Phones["example.com"`, `Phones["a"]b`} {
+		if _, err := Compile(name); err == nil {
+			t.Errorf("compile %s should return error", name)
+		}
+	}
+	if err := Set(&p, `Departments["0"].Name`, "D"); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected quoted key is not an index, but was %v", err)
+	}
+}
+
+func TestNegativeIndexes(t *testing.T) {
+	p := &Person{
+		Departments: []*Department{{Name: "D1"}, {Name: "D2"}, {Name: "D3"}},
+	}
+
+	if v := MustGet(p, "Departments[-1].Name"); v != "D3" {
+		t.Errorf("expected D3, but was %v", v)
+	}
+	if v := MustGet(p, "Departments[-3].Name"); v != "D1" {
+		t.Errorf("expected D1, but was %v", v)
+	}
+
+	if err := Set(p, "Departments[-1].Name", "Latest"); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Departments) != 3 || p.Departments[2].Name != "Latest" {
+		t.Errorf("expected last department changed, but was %+v", p.Departments[2])
+	}
+
+	if err := Set(p, "Departments[-4].Name", "D0"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("expected index out of range, but was %v", err)
+	}
+	if len(p.Departments) != 3 {
+		t.Errorf("expected no growth for negative index, but was %d", len(p.Departments))
+	}
+	if _, err := Get(p, "Departments[-4].Name"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("expected index out of range, but was %v", err)
+	}
+
+	if err := Delete(p, "Departments[-1]"); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Departments) != 2 || p.Departments[1].Name != "D2" {
+		t.Errorf("expected last department deleted, but was %+v", p.Departments)
+	}
+	if err := Delete(p, "Departments[-3]"); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("expected index out of range, but was %v", err)
+	}
+}
+
+func TestRanges(t *testing.T) {
+	newPerson := func() *Person {
+		return &Person{
+			Departments: []*Department{{Name: "D0"}, {Name: "D1"}, {Name: "D2"}, {Name: "D3"}},
+		}
+	}
+	p := newPerson()
+
+	var rangeCases = []struct {
+		name     string
+		expected string
+	}{
+		{name: "Departments[1:3]", expected: "[D1 D2]"},
+		{name: "Departments[:2]", expected: "[D0 D1]"},
+		{name: "Departments[2:]", expected: "[D2 D3]"},
+		{name: "Departments[-1:]", expected: "[D3]"},
+		{name: "Departments[:]", expected: "[D0 D1 D2 D3]"},
+		{name: "Departments[3:10]", expected: "[D3]"},
+		{name: "Departments[3:1]", expected: "[]"},
+	}
+	for _, c := range rangeCases {
+		t.Run(c.name, func(t *testing.T) {
+			v, err := Get(p, c.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, d := range v.([]*Department) {
+				names = append(names, d.Name)
+			}
+			if actual := fmt.Sprint(names); actual != c.expected {
+				t.Errorf("expected %s, but was %s", c.expected, actual)
+			}
+		})
+	}
+
+	if typ := GetType(p, "Departments[1:3]"); typ == nil || typ.String() != "[]*reflectutils_test.Department" {
+		t.Errorf("expected slice type, but was %v", typ)
+	}
+	if typ := GetType(p, "Departments[1:3].Name"); typ == nil || typ.String() != "string" {
+		t.Errorf("expected string, but was %v", typ)
+	}
+
+	count, err := SetAll(p, "Departments[1:3].Name", "Archived")
+	if err != nil || count != 2 {
+		t.Fatalf("expected 2 set, but was %d, %v", count, err)
+	}
+	if p.Departments[0].Name != "D0" || p.Departments[1].Name != "Archived" || p.Departments[2].Name != "Archived" || p.Departments[3].Name != "D3" {
+		t.Errorf("expected range set, but was %+v", p.Departments)
+	}
+	if err = Set(p, "Departments[1:3].Name", "D"); err == nil {
+		t.Errorf("expected Set with range returns error")
+	}
+
+	p = newPerson()
+	if err = Delete(p, "Departments[1:3]"); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Departments) != 2 || p.Departments[0].Name != "D0" || p.Departments[1].Name != "D3" {
+		t.Errorf("expected range deleted, but was %+v", p.Departments)
+	}
+
+	s := &Shape{ID: [4]byte{1, 2, 3, 4}}
+	if v := MustGet(s, "ID[2:]"); fmt.Sprint(v) != "[3 4]" {
+		t.Errorf("expected [3 4], but was %v", v)
+	}
+	if err = Delete(s, "ID[:2]"); err != nil || s.ID != [4]byte{0, 0, 3, 4} {
+		t.Errorf("expected array elements set to zero, but was %v, %v", err, s.ID)
+	}
+
+	p.Phones = map[string]string{"1:3": "x"}
+	if _, err = Get(p, "Phones[1:3]"); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expected unsupported kind for range on map, but was %v", err)
+	}
+	if v := MustGet(p, `Phones["1:3"]`); v != "x" {
+		t.Errorf("expected quoted key, but was %v", v)
+	}
+	if v := MustGet(p, `Phones.1:3`); v != "x" {
+		t.Errorf("expected dotted key, but was %v", v)
+	}
+}
+
+type Config struct {
+	Name  string
+	Extra interface{}
+}
+
+func TestInterfaceDocuments(t *testing.T) {
+	var cfg map[string]interface{}
+
+	var setCases = []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "db.pool.size", value: 10},
+		{name: "db.hosts[]", value: "a.example.com"},
+		{name: "db.hosts[]", value: "b.example.com"},
+		{name: "db.replicas[1].port", value: 5432},
+		{name: `db.pool["max.idle"]`, value: 2},
+	}
+	for _, c := range setCases {
+		if err := Set(&cfg, c.name, c.value); err != nil {
+			t.Fatalf("set %s: %v", c.name, err)
+		}
+	}
+
+	expected := `map[db:map[hosts:[a.example.com b.example.com] pool:map[max.idle:2 size:10] replicas:[<nil> map[port:5432]]]]`
+	if actual := fmt.Sprint(cfg); actual != expected {
+		t.Errorf("expected %s, but was %s", expected, actual)
+	}
+	if err := Set(&cfg, "errors.404.message", "not found"); err != nil {
+		t.Fatal(err)
+	}
+	if v := MustGet(cfg, "errors"); fmt.Sprint(v) != "map[404:map[message:not found]]" {
+		t.Errorf("expected numeric dotted segment as map key, but was %v", v)
+	}
+	delete(cfg, "errors")
+
+	var getCases = []struct {
+		name     string
+		expected interface{}
+	}{
+		{name: "db.pool.size", expected: 10},
+		{name: "db.hosts[-1]", expected: "b.example.com"},
+		{name: "db.replicas[1].port", expected: 5432},
+		{name: "db.replicas[0]", expected: nil},
+		{name: "db.missing.size", expected: nil},
+	}
+	for _, c := range getCases {
+		if v := MustGet(cfg, c.name); v != c.expected {
+			t.Errorf("get %s: expected %v, but was %v", c.name, c.expected, v)
+		}
+	}
+
+	if err := Set(&cfg, "db.pool.size.value", 1); !errors.Is(err, ErrUnsupportedKind) {
+		t.Errorf("expected unsupported kind for int in interface, but was %v", err)
+	}
+
+	if err := Delete(&cfg, "db.hosts[0]"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Delete(&cfg, `db.pool["size"]`); err != nil {
+		t.Fatal(err)
+	}
+	expected = `map[db:map[hosts:[b.example.com] pool:map[max.idle:2] replicas:[<nil> map[port:5432]]]]`
+	if actual := fmt.Sprint(cfg); actual != expected {
+		t.Errorf("expected %s, but was %s", expected, actual)
+	}
+
+	var c *Config
+	if err := Set(&c, "Extra.theme.color", "dark"); err != nil {
+		t.Fatal(err)
+	}
+	if v := MustGet(c, "Extra.theme.color"); v != "dark" {
+		t.Errorf("expected dark, but was %v", v)
+	}
+	c.Extra = &Config{}
+	if err := Set(&c, "Extra.Name", "nested"); err != nil || c.Extra.(*Config).Name != "nested" {
+		t.Errorf("expected struct in interface set, but was %v, %+v", err, c.Extra)
+	}
+
+	var typeCases = []struct {
+		obj      interface{}
+		name     string
+		expected string
+	}{
+		{obj: c, name: "Extra", expected: "interface {}"},
+		{obj: c, name: "Extra.Name", expected: "string"},
+		{obj: c, name: "Extra.not.there", expected: "<nil>"},
+		{obj: cfg, name: "db.pool.size", expected: "interface {}"},
+		{obj: cfg, name: "db.hosts[0]", expected: "interface {}"},
+		{obj: cfg, name: "not.there.at.all", expected: "<nil>"},
+	}
+	for _, tc := range typeCases {
+		if typ := fmt.Sprint(GetType(tc.obj, tc.name)); typ != tc.expected {
+			t.Errorf("type of %s: expected %s, but was %s", tc.name, tc.expected, typ)
+		}
+	}
+
+	if err := MergePatch(&cfg, []byte(`{"db":{"pool":{"max.idle":null,"size":5}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if v := MustGet(cfg, `db.pool`); fmt.Sprint(v) != "map[size:5]" {
+		t.Errorf("expected pool merged, but was %v", v)
+	}
+
+	matches, err := GetAll(cfg, "..port")
+	if err != nil || fmt.Sprintf("%+v", matches) != `[{Path:db.replicas[1].port Value:5432}]` {
+		t.Errorf("expected port found in document, but was %+v, %v", matches, err)
+	}
+}
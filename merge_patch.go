@@ -0,0 +1,133 @@
+package reflectutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MergePatch applies a RFC 7396 JSON Merge Patch document to the value i points to. Objects in the patch
+// are merged into structs and maps, `null` deletes map keys and sets fields to zero value like Delete,
+// and other values are decoded into the type of their field with encoding/json and set. Fields are resolved
+// by json tags like ParsePointer, errors are *PathError with the JSON Pointer of the failed value,
+// and i is left unchanged if any value fails.
+func MergePatch(i interface{}, patch []byte, opts ...Option) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+
+	if !json.Valid(patch) {
+		return errors.New("invalid JSON merge patch")
+	}
+
+	rv := reflect.ValueOf(i)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrNotAddressable
+	}
+
+	doc := deepCopy(rv, map[visit]reflect.Value{})
+	if err = mergePatch(doc.Interface(), "", patch, opts); err != nil {
+		return
+	}
+
+	rv.Elem().Set(doc.Elem())
+	return
+}
+
+// mergePatch merges patch into the value of pointer in i.
+func mergePatch(i interface{}, pointer string, patch json.RawMessage, opts []Option) (err error) {
+	var p *Path
+	p, err = ParsePointer(pointer, opts...)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = p.pathError("merge", err)
+	}()
+
+	if !isJSONObject(patch) || !p.mergeable(i) {
+		// an object replaces a target that isn't an object like it is merged into {}, so its nulls are dropped
+		patch, err = dropNulls(patch)
+		if err != nil {
+			return
+		}
+
+		var value interface{}
+		value, err = p.decodeJSON(i, patch)
+		if err != nil {
+			return
+		}
+		return p.Set(i, value)
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(patch, &fields); err != nil {
+		return
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		child := pointer + "/" + pointerEscaper.Replace(k)
+		if string(bytes.TrimSpace(fields[k])) != "null" {
+			if err = mergePatch(i, child, fields[k], opts); err != nil {
+				return
+			}
+			continue
+		}
+
+		var cp *Path
+		cp, err = ParsePointer(child, opts...)
+		if err != nil {
+			return
+		}
+		if !cp.Has(i) {
+			continue
+		}
+		if err = cp.Delete(i); err != nil {
+			return cp.pathError("merge", err)
+		}
+	}
+	return
+}
+
+// mergeable reports whether the value of the path in i is a struct or a map that an object is merged into.
+func (p *Path) mergeable(i interface{}) bool {
+	t, _ := p.valueType(i, p.tokens)
+	return t != nil && (t.Kind() == reflect.Struct || t.Kind() == reflect.Map)
+}
+
+// dropNulls removes the null members of the objects in data, nulls in arrays are kept.
+func dropNulls(data json.RawMessage) (_ json.RawMessage, err error) {
+	if !isJSONObject(data) {
+		return data, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return
+	}
+	for k, v := range fields {
+		if string(bytes.TrimSpace(v)) == "null" {
+			delete(fields, k)
+			continue
+		}
+		if fields[k], err = dropNulls(v); err != nil {
+			return
+		}
+	}
+	return json.Marshal(fields)
+}
+
+func isJSONObject(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '{'
+}
@@ -0,0 +1,93 @@
+package reflectutils_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+func TestMergePatch(t *testing.T) {
+	c := &Contact{
+		PhoneNumber: "911",
+		Nickname:    "Felix",
+		Company:     &Company{Name: "The Plant"},
+	}
+
+	err := MergePatch(c, []byte(`{"phone_number":null,"company":{"Phone":{"Number":"110"}},"Nickname":"Bin"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.PhoneNumber != "" || c.Nickname != "Bin" {
+		t.Errorf("expected fields set, but was %+v", c)
+	}
+	if c.Company.Name != "The Plant" || c.Company.Phone == nil || c.Company.Phone.Number != "110" {
+		t.Errorf("expected company merged, but was %+v", c.Company)
+	}
+
+	if err = MergePatch(c, []byte(`{"company":null}`)); err != nil || c.Company != nil {
+		t.Errorf("expected company set to nil, but was %v, %+v", err, c.Company)
+	}
+
+	team := newTeam()
+	err = MergePatch(team, []byte(`{"labels":{"env":null,"tier":"1","missing":null},"members":[{"name":"Anna"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := team.Labels["env"]; ok || team.Labels["tier"] != "1" || len(team.Labels) != 1 {
+		t.Errorf("expected labels merged, but was %+v", team.Labels)
+	}
+	if len(team.Members) != 1 || team.Members[0].Name != "Anna" {
+		t.Errorf("expected members replaced, but was %+v", team.Members)
+	}
+
+	team = newTeam()
+	err = MergePatch(team, []byte(`{"name":"Changed","members":[{"name":"Anna"}],"labels":{"env":1}}`))
+	var pe *PathError
+	if !errors.As(err, &pe) || !errors.Is(err, ErrTypeMismatch) || pe.Path != "/labels/env" || pe.Op != "merge" {
+		t.Fatalf("expected type mismatch at /labels/env, but was %v", err)
+	}
+	if team.Name != "Core" || len(team.Members) != 2 || team.Labels["env"] != "prod" {
+		t.Errorf("expected team unchanged, but was %+v", team)
+	}
+
+	if err = MergePatch(team, []byte(`{"nmae":"Core"}`)); !errors.As(err, &pe) || !errors.Is(err, NoSuchFieldError) || pe.Path != "/nmae" {
+		t.Errorf("expected no such field at /nmae, but was %v", err)
+	}
+	if err = MergePatch(team, []byte(`{"name":`)); err == nil {
+		t.Errorf("expected invalid JSON error")
+	}
+
+	doc := map[string]interface{}{"a": "text"}
+	if err = MergePatch(&doc, []byte(`{"a":{"b":null,"c":[1,null]},"d":{"e":null}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if v := fmt.Sprint(doc); v != "map[a:map[c:[1 <nil>]] d:map[]]" {
+		t.Errorf("expected nulls dropped in document, but was %s", v)
+	}
+
+	var cfg *Config
+	if err = MergePatch(&cfg, []byte(`{"Extra":{"x":{"y":null,"z":2}}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if v := fmt.Sprint(cfg.Extra); v != "map[x:map[z:2]]" {
+		t.Errorf("expected nulls dropped in interface, but was %s", v)
+	}
+
+	cfg = &Config{Extra: map[string]int{"a": 1}}
+	if err = MergePatch(cfg, []byte(`{"Extra":{"a":7,"b":8}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if v := fmt.Sprint(cfg.Extra); v != "map[a:7 b:8]" {
+		t.Errorf("expected typed map in interface merged, but was %s", v)
+	}
+
+	cfg = &Config{Extra: &Phone{}}
+	if err = MergePatch(cfg, []byte(`{"Extra":{"Number":"911"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	if v := cfg.Extra.(*Phone).Number; v != "911" {
+		t.Errorf("expected struct in interface merged, but was %s", v)
+	}
+}
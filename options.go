@@ -0,0 +1,55 @@
+package reflectutils
+
+import (
+	"time"
+)
+
+// Option changes how the segments of a path are resolved.
+type Option func(o *options)
+
+type options struct {
+	tagName     string
+	strict      bool
+	timeLayouts []string
+}
+
+// defaultTimeLayouts are the layouts strings are parsed into time.Time with, if WithTimeLayouts isn't given.
+var defaultTimeLayouts = []string{time.RFC3339Nano, time.DateOnly}
+
+func newOptions(opts []Option) (o options) {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return
+}
+
+func (o *options) layouts() []string {
+	if len(o.timeLayouts) > 0 {
+		return o.timeLayouts
+	}
+	return defaultTimeLayouts
+}
+
+// WithTagName resolves struct fields by the name in the given struct tag, like `json:"phone_number"`.
+// Fields without the tag are resolved by their field name, and fields tagged with "-" can't be addressed.
+func WithTagName(name string) Option {
+	return func(o *options) {
+		o.tagName = name
+	}
+}
+
+// WithStrictMatching requires path segments to match field names with exact case, and returns
+// an *AmbiguousFieldError instead of NoSuchFieldError when embedded structs promote colliding names.
+func WithStrictMatching() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithTimeLayouts parses strings into time.Time fields with layouts instead of RFC 3339 and date only,
+// integers and strings of digits are always parsed as Unix seconds, and GetAs formats time.Time to string with the first layout.
+func WithTimeLayouts(layouts ...string) Option {
+	return func(o *options) {
+		o.timeLayouts = layouts
+	}
+}
@@ -0,0 +1,121 @@
+package reflectutils_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+type Contact struct {
+	PhoneNumber string   `json:"phone_number,omitempty"`
+	Company     *Company `json:"company"`
+	Nickname    string
+	Secret      string `json:"-"`
+}
+
+func TestWithTagName(t *testing.T) {
+	var c *Contact
+	json := WithTagName("json")
+
+	if err := Set(&c, "phone_number", "911", json); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(&c, "company.Name", "The Plant", json); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(&c, "nickname", "Felix", json); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.PhoneNumber != "911" || c.Company.Name != "The Plant" || c.Nickname != "Felix" {
+		t.Errorf("set by json tag failed, %+v", c)
+	}
+
+	if v := MustGet(c, "phone_number", json); v != "911" {
+		t.Errorf("expected 911, but was %v", v)
+	}
+
+	if typ := GetType(c, "company.Phone2", json); typ != nil {
+		t.Errorf("field with json:\"-\" should not be addressable, but was %v", typ)
+	}
+	if _, err := Get(c, "company.Phone2", json); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field, but was %v", err)
+	}
+	if err := Set(&c, "Secret", "1", json); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field, but was %v", err)
+	}
+
+	if err := Set(&c, "phone_number", "1"); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field without tag name, but was %v", err)
+	}
+
+	if err := Delete(c, "phone_number", json); err != nil || c.PhoneNumber != "" {
+		t.Errorf("delete by json tag failed, %v, %+v", err, c)
+	}
+}
+
+type Audit struct {
+	ID      int
+	Updated string
+}
+
+type Owner struct {
+	ID   int
+	Name string
+}
+
+type Document struct {
+	Audit
+	*Owner
+	Title string
+	TITLE string
+}
+
+func TestWithStrictMatching(t *testing.T) {
+	var d *Document
+	strict := WithStrictMatching()
+
+	if err := Set(&d, "Title", "Go", strict); err != nil {
+		t.Fatal(err)
+	}
+	if err := Set(&d, "TITLE", "GO", strict); err != nil {
+		t.Fatal(err)
+	}
+	if d.Title != "Go" || d.TITLE != "GO" {
+		t.Errorf("expected exact field set, but was %+v", d)
+	}
+
+	if err := Set(&d, "title", "go"); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field for fields differ only by case, but was %v", err)
+	}
+	if err := Set(&d, "title", "go", strict); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field for wrong case, but was %v", err)
+	}
+
+	if err := Set(&d, "Name", "Felix", strict); err != nil || d.Owner.Name != "Felix" {
+		t.Errorf("expected promoted field set, but was %v, %+v", err, d.Owner)
+	}
+
+	var ambiguous *AmbiguousFieldError
+	err := Set(&d, "ID", 1, strict)
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected ambiguous field error, but was %v", err)
+	}
+	if ambiguous.Error() != `ambiguous field "ID" matches Audit.ID, Owner.ID` {
+		t.Errorf("unexpected error message %s", ambiguous)
+	}
+
+	if _, err = Get(d, "ID", strict); !errors.As(err, &ambiguous) {
+		t.Errorf("expected ambiguous field error for Get, but was %v", err)
+	}
+	if err = Delete(d, "ID", strict); !errors.As(err, &ambiguous) {
+		t.Errorf("expected ambiguous field error for Delete, but was %v", err)
+	}
+	if typ := GetType(d, "ID", strict); typ != nil {
+		t.Errorf("expected nil type for ambiguous field, but was %v", typ)
+	}
+	if err = Set(&d, "ID", 1); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field without strict mode, but was %v", err)
+	}
+}
@@ -0,0 +1,258 @@
+package reflectutils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyPatch applies a RFC 6902 JSON Patch document to the value i points to, with the operations
+// add, remove, replace, move, copy and test. Paths are JSON Pointers resolved by json tags, see ParsePointer,
+// and values are decoded into the type of the path with encoding/json. Operations are applied to a deep copy
+// that replaces the value i points to at the end, so i is left unchanged if any of them fails.
+func ApplyPatch(i interface{}, patch []byte, opts ...Option) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+	}()
+
+	var ops []patchOperation
+	if err = json.Unmarshal(patch, &ops); err != nil {
+		return
+	}
+
+	rv := reflect.ValueOf(i)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return ErrNotAddressable
+	}
+
+	doc := deepCopy(rv, map[visit]reflect.Value{})
+	for _, op := range ops {
+		if err = op.apply(doc.Interface(), opts); err != nil {
+			return
+		}
+	}
+
+	rv.Elem().Set(doc.Elem())
+	return
+}
+
+func (op *patchOperation) apply(i interface{}, opts []Option) (err error) {
+	var p *Path
+	p, err = ParsePointer(op.Path, opts...)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err = p.pathError(op.Op, err)
+	}()
+
+	switch op.Op {
+	case "add":
+		var value interface{}
+		value, err = op.decode(i, p)
+		if err != nil {
+			return
+		}
+		return p.add(i, value)
+
+	case "remove":
+		if !p.Has(i) {
+			return NoSuchFieldError
+		}
+		return p.Delete(i)
+
+	case "replace":
+		if !p.Has(i) {
+			return NoSuchFieldError
+		}
+		var value interface{}
+		value, err = op.decode(i, p)
+		if err != nil {
+			return
+		}
+		return p.Set(i, value)
+
+	case "move", "copy":
+		var from *Path
+		from, err = ParsePointer(op.From, opts...)
+		if err != nil {
+			return
+		}
+		if !from.Has(i) {
+			return fmt.Errorf("%w: from %s", NoSuchFieldError, op.From)
+		}
+
+		var value interface{}
+		value, err = from.Get(i)
+		if err != nil {
+			return
+		}
+
+		if op.Op == "copy" {
+			if value != nil {
+				value = deepCopy(reflect.ValueOf(value), map[visit]reflect.Value{}).Interface()
+			}
+			return p.add(i, value)
+		}
+
+		if op.From == op.Path {
+			return
+		}
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return fmt.Errorf("can not move %s into its child", op.From)
+		}
+		if err = from.Delete(i); err != nil {
+			return
+		}
+		return p.add(i, value)
+
+	case "test":
+		var value interface{}
+		value, err = op.decode(i, p)
+		if err != nil {
+			return
+		}
+
+		var actual interface{}
+		actual, err = p.Get(i)
+		if err != nil {
+			return
+		}
+		if !p.Has(i) {
+			return ErrTestFailed
+		}
+		var equal bool
+		equal, err = jsonEqual(actual, value)
+		if err != nil {
+			return
+		}
+		if !equal {
+			return ErrTestFailed
+		}
+		return
+	}
+
+	return fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// decode decodes the value of the operation into the type of path p in i.
+func (op *patchOperation) decode(i interface{}, p *Path) (value interface{}, err error) {
+	if op.Value == nil {
+		err = errors.New("missing value")
+		return
+	}
+	return p.decodeJSON(i, op.Value)
+}
+
+// decodeJSON decodes data into a new value of the type of the path in i, the value is a pointer to it.
+func (p *Path) decodeJSON(i interface{}, data json.RawMessage) (value interface{}, err error) {
+	var t reflect.Type
+	t, err = p.typeOf(i, p.tokens)
+	if err == nil && t == nil {
+		// paths under an interface{} that has no value yet can hold any JSON value
+		t, err = p.getType(reflect.TypeOf(i), p.tokens)
+	}
+	if err != nil {
+		return
+	}
+	if t == nil {
+		err = NoSuchFieldError
+		return
+	}
+
+	v := reflect.New(t)
+	if err = json.Unmarshal(data, v.Interface()); err != nil {
+		err = fmt.Errorf("%w: %s", ErrTypeMismatch, err)
+		return
+	}
+	return v.Interface(), nil
+}
+
+// add sets value to the path, an index of a slice inserts value before the element at it,
+// like the add operation of JSON Patch, while Set replaces the element. The parent of the path must exist,
+// since Set would create it.
+func (p *Path) add(i interface{}, value interface{}) (err error) {
+	n := len(p.tokens)
+	if n > 0 {
+		if _, found, gerr := p.get(reflect.ValueOf(i), p.tokens[:n-1]); gerr != nil || !found {
+			return fmt.Errorf("%w: parent of %s", NoSuchFieldError, p.path)
+		}
+	}
+	if n == 0 || !p.tokens[n-1].IsArray || p.tokens[n-1].IsAppendingArray {
+		return p.Set(i, value)
+	}
+
+	key, parent := p.tokens[n-1], p.tokens[:n-1]
+	t, err := p.valueType(i, parent)
+	if err != nil {
+		return
+	}
+	if t == nil || t.Kind() != reflect.Slice {
+		return p.Set(i, value)
+	}
+
+	sv, _, err := p.get(reflect.ValueOf(i), parent)
+	if err != nil {
+		return
+	}
+	for sv.Kind() == reflect.Ptr || sv.Kind() == reflect.Interface {
+		sv = sv.Elem()
+	}
+	length := 0
+	if sv.IsValid() {
+		length = sv.Len()
+	}
+	if key.ArrayIndex > length {
+		return p.wrapError(key, t, ErrIndexOutOfRange)
+	}
+
+	elem := reflect.New(t.Elem()).Elem()
+	if err = assign(elem, value, &p.opts); err != nil {
+		return p.wrapError(key, t, err)
+	}
+
+	newSlice := reflect.MakeSlice(t, 0, length+1)
+	if sv.IsValid() {
+		newSlice = reflect.AppendSlice(newSlice, sv.Slice(0, key.ArrayIndex))
+	}
+	newSlice = reflect.Append(newSlice, elem)
+	if sv.IsValid() {
+		newSlice = reflect.AppendSlice(newSlice, sv.Slice(key.ArrayIndex, length))
+	}
+	return p.set(i, parent, newSlice.Interface())
+}
+
+// jsonEqual reports whether a and b are the same JSON value, so that numbers of different types
+// like int and float64 in interface{} values are equal.
+func jsonEqual(a, b interface{}) (equal bool, err error) {
+	ja, err := normalizeJSON(a)
+	if err != nil {
+		return
+	}
+	jb, err := normalizeJSON(b)
+	if err != nil {
+		return
+	}
+	return reflect.DeepEqual(ja, jb), nil
+}
+
+// normalizeJSON marshals i to JSON and decodes it back into interface{}.
+func normalizeJSON(i interface{}) (value interface{}, err error) {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &value)
+	return
+}
@@ -0,0 +1,188 @@
+package reflectutils_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+func newTeam() *Team {
+	return &Team{
+		Name:    "Core",
+		Members: []*Member{{Name: "Felix"}, {Name: "Bin"}},
+		Labels:  map[string]string{"env": "prod"},
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	var patchCases = []struct {
+		name   string
+		patch  string
+		verify func(team *Team) bool
+	}{
+		{
+			name:  "add field and map key",
+			patch: `[{"op":"add","path":"/name","value":"Platform"},{"op":"add","path":"/labels/tier","value":"1"}]`,
+			verify: func(team *Team) bool {
+				return team.Name == "Platform" && team.Labels["tier"] == "1" && team.Labels["env"] == "prod"
+			},
+		},
+		{
+			name:  "add inserts and appends",
+			patch: `[{"op":"add","path":"/members/1","value":{"name":"Anna"}},{"op":"add","path":"/members/-","value":{"name":"Juice"}}]`,
+			verify: func(team *Team) bool {
+				return len(team.Members) == 4 && team.Members[0].Name == "Felix" && team.Members[1].Name == "Anna" &&
+					team.Members[2].Name == "Bin" && team.Members[3].Name == "Juice"
+			},
+		},
+		{
+			name:  "remove",
+			patch: `[{"op":"remove","path":"/members/0"},{"op":"remove","path":"/labels/env"}]`,
+			verify: func(team *Team) bool {
+				return len(team.Members) == 1 && team.Members[0].Name == "Bin" && len(team.Labels) == 0
+			},
+		},
+		{
+			name:  "replace",
+			patch: `[{"op":"replace","path":"/members/1","value":{"name":"Anna","email":"anna@example.com"}}]`,
+			verify: func(team *Team) bool {
+				return len(team.Members) == 2 && team.Members[1].Name == "Anna" && team.Members[1].Email == "anna@example.com"
+			},
+		},
+		{
+			name:  "move",
+			patch: `[{"op":"move","from":"/members/0/name","path":"/labels/owner"}]`,
+			verify: func(team *Team) bool {
+				return team.Labels["owner"] == "Felix" && team.Members[0].Name == ""
+			},
+		},
+		{
+			name:  "copy",
+			patch: `[{"op":"copy","from":"/members/1","path":"/members/0"}]`,
+			verify: func(team *Team) bool {
+				return len(team.Members) == 3 && team.Members[0].Name == "Bin" && team.Members[0] != team.Members[2]
+			},
+		},
+		{
+			name:  "test",
+			patch: `[{"op":"test","path":"/members/1","value":{"name":"Bin"}},{"op":"test","path":"/name","value":"Core"},{"op":"replace","path":"/name","value":"Tested"}]`,
+			verify: func(team *Team) bool {
+				return team.Name == "Tested"
+			},
+		},
+	}
+
+	for _, c := range patchCases {
+		t.Run(c.name, func(t *testing.T) {
+			team := newTeam()
+			if err := ApplyPatch(team, []byte(c.patch)); err != nil {
+				t.Fatal(err)
+			}
+			if !c.verify(team) {
+				t.Errorf("unexpected result %+v, %+v", team, team.Members)
+			}
+		})
+	}
+
+	doc := map[string]interface{}{"n": 1, "list": []int{1, 2}}
+	if err := ApplyPatch(&doc, []byte(`[{"op":"test","path":"/n","value":1},{"op":"test","path":"/list","value":[1,2.0]}]`)); err != nil {
+		t.Errorf("expected ints to equal JSON numbers, but was %v", err)
+	}
+
+	cfg := &Config{Extra: map[string]int{"a": 1}}
+	if err := ApplyPatch(cfg, []byte(`[{"op":"replace","path":"/Extra/a","value":5},{"op":"add","path":"/Extra/b","value":6}]`)); err != nil {
+		t.Fatal(err)
+	}
+	if v := fmt.Sprint(cfg.Extra); v != "map[a:5 b:6]" {
+		t.Errorf("expected typed map in interface patched, but was %s", v)
+	}
+
+	cfg = &Config{Extra: &Phone{}}
+	if err := ApplyPatch(cfg, []byte(`[{"op":"add","path":"/Extra/Number","value":"911"}]`)); err != nil {
+		t.Fatal(err)
+	}
+	if v := cfg.Extra.(*Phone).Number; v != "911" {
+		t.Errorf("expected struct in interface patched, but was %s", v)
+	}
+}
+
+func TestApplyPatchAtomic(t *testing.T) {
+	var errorCases = []struct {
+		name     string
+		patch    string
+		expected error
+	}{
+		{
+			name:     "test failed",
+			patch:    `[{"op":"replace","path":"/name","value":"Changed"},{"op":"add","path":"/members/-","value":{"name":"Anna"}},{"op":"test","path":"/name","value":"Core"}]`,
+			expected: ErrTestFailed,
+		},
+		{
+			name:     "remove missing key",
+			patch:    `[{"op":"remove","path":"/labels/env"},{"op":"remove","path":"/labels/env"}]`,
+			expected: NoSuchFieldError,
+		},
+		{
+			name:     "replace with wrong type",
+			patch:    `[{"op":"remove","path":"/members/0"},{"op":"replace","path":"/name","value":1}]`,
+			expected: ErrTypeMismatch,
+		},
+		{
+			name:     "add to missing element",
+			patch:    `[{"op":"add","path":"/members/5/name","value":"Anna"}]`,
+			expected: NoSuchFieldError,
+		},
+		{
+			name:     "copy to missing element",
+			patch:    `[{"op":"copy","from":"/name","path":"/members/2/name"}]`,
+			expected: NoSuchFieldError,
+		},
+		{
+			name:     "move to missing element",
+			patch:    `[{"op":"move","from":"/members/0/name","path":"/members/3/name"}]`,
+			expected: NoSuchFieldError,
+		},
+		{
+			name:     "insert past the end",
+			patch:    `[{"op":"add","path":"/members/3","value":{"name":"Anna"}}]`,
+			expected: ErrIndexOutOfRange,
+		},
+	}
+
+	for _, c := range errorCases {
+		t.Run(c.name, func(t *testing.T) {
+			team := newTeam()
+			members := team.Members
+			err := ApplyPatch(team, []byte(c.patch))
+			if !errors.Is(err, c.expected) {
+				t.Fatalf("expected %v, but was %v", c.expected, err)
+			}
+			if team.Name != "Core" || len(team.Members) != 2 || team.Members[0] != members[0] || team.Members[0].Name != "Felix" || team.Labels["env"] != "prod" {
+				t.Errorf("expected team unchanged, but was %+v", team)
+			}
+		})
+	}
+
+	doc := map[string]map[string]int{"a": {"b": 1}}
+	if err := ApplyPatch(&doc, []byte(`[{"op":"add","path":"/nested/b","value":1}]`)); !errors.Is(err, NoSuchFieldError) || len(doc) != 1 {
+		t.Errorf("expected no such field and doc unchanged, but was %v, %v", err, doc)
+	}
+	document := map[string]interface{}{"name": "Core"}
+	if err := ApplyPatch(&document, []byte(`[{"op":"add","path":"/nested/a/b","value":1}]`)); !errors.Is(err, NoSuchFieldError) || len(document) != 1 {
+		t.Errorf("expected no such field and document unchanged, but was %v, %v", err, document)
+	}
+
+	team := newTeam()
+	var pe *PathError
+	if err := ApplyPatch(team, []byte(`[{"op":"test","path":"/name","value":"Other"}]`)); !errors.As(err, &pe) || pe.Op != "test" || pe.Path != "/name" {
+		t.Errorf("expected path error of the test operation, but was %v", err)
+	}
+	if err := ApplyPatch(team, []byte(`[{"op":"rename","path":"/name"}]`)); err == nil {
+		t.Errorf("expected unknown operation error")
+	}
+	if err := ApplyPatch(*team, []byte(`[]`)); !errors.Is(err, ErrNotAddressable) {
+		t.Errorf("expected not addressable, but was %v", err)
+	}
+}
@@ -0,0 +1,508 @@
+package reflectutils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Path is a parsed path that can be used many times with Get, Set, Delete and Type
+// without parsing the path string again.
+type Path struct {
+	path    string
+	tokens  []*dotToken
+	opts    options
+	pointer bool
+}
+
+// Compile parses a path so that it can be reused, errors of the path syntax are returned here
+// instead of in the middle of setting a value.
+func Compile(path string, opts ...Option) (p *Path, err error) {
+	var tokens []*dotToken
+	tokens, err = parsePath(path)
+	if err != nil {
+		return
+	}
+
+	p = &Path{path: path, tokens: tokens, opts: newOptions(opts)}
+	return
+}
+
+// MustCompile is like Compile but panics if the path can not be parsed.
+func MustCompile(path string, opts ...Option) *Path {
+	p, err := Compile(path, opts...)
+	if err != nil {
+		panic(fmt.Sprintf("%s: %s", err, path))
+	}
+	return p
+}
+
+// String returns the path that was compiled.
+func (p *Path) String() string {
+	return p.path
+}
+
+// Get value of a struct by the compiled path, a path with filters gets a []interface{} of every match.
+func (p *Path) Get(i interface{}) (value interface{}, err error) {
+	// the traversal checks kinds before calling reflect, recover is only a safety net for anything missed
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+		err = p.pathError("get", err)
+	}()
+
+	if p.hasFilter() {
+		var matches []Match
+		matches, err = p.GetAll(i)
+		if err != nil {
+			return
+		}
+		values := make([]interface{}, 0, len(matches))
+		for _, m := range matches {
+			values = append(values, m.Value)
+		}
+		value = values
+		return
+	}
+
+	if p.hasWildcard() && !p.isSubslice() {
+		err = errWildcard
+		return
+	}
+
+	var v reflect.Value
+	v, _, err = p.get(reflect.ValueOf(i), p.tokens)
+	if err != nil || !v.IsValid() {
+		return
+	}
+
+	value = v.Interface()
+	return
+}
+
+// Lookup is like Get, but found is false if a nil pointer, a missing map key, or an index
+// past the end of a slice is in the path, while nil or zero values at the end of the path are found.
+func (p *Path) Lookup(i interface{}) (value interface{}, found bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+		err = p.pathError("lookup", err)
+	}()
+
+	if p.hasWildcard() && !p.isSubslice() {
+		err = errWildcard
+		return
+	}
+
+	var v reflect.Value
+	v, found, err = p.get(reflect.ValueOf(i), p.tokens)
+	if err != nil || !found || !v.IsValid() {
+		found = found && err == nil
+		return
+	}
+
+	value = v.Interface()
+	return
+}
+
+// Has reports whether the value of the compiled path exists.
+func (p *Path) Has(i interface{}) bool {
+	_, found, _ := p.Lookup(i)
+	return found
+}
+
+// Set value of a struct by the compiled path, a path with filters sets every match.
+func (p *Path) Set(i interface{}, value interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+		err = p.pathError("set", err)
+	}()
+
+	if p.hasFilter() {
+		_, err = p.SetAll(i, value)
+		return
+	}
+
+	if p.hasWildcard() {
+		err = errWildcard
+		return
+	}
+
+	return p.set(i, p.tokens, value)
+}
+
+// Delete removes a slice element or map key, or sets a field to zero value by the compiled path,
+// a path with filters deletes every match.
+func (p *Path) Delete(i interface{}) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+		err = p.pathError("delete", err)
+	}()
+
+	if p.hasFilter() {
+		_, err = p.DeleteAll(i)
+		return
+	}
+
+	if p.hasWildcard() && !p.isSubslice() {
+		err = errWildcard
+		return
+	}
+
+	tokens := p.tokens
+	if p.pointer {
+		// a JSON Pointer removes map keys and elements, like the remove operation of JSON Patch
+		tokens = p.keyTokens(i, tokens)
+	}
+	return p.deletePath(i, tokens)
+}
+
+// Type returns the type of the value the compiled path points to, nil if the path doesn't exist.
+// Types under an interface{} are resolved by its value in i.
+func (p *Path) Type(i interface{}) reflect.Type {
+	t, _ := p.typeOf(i, p.tokens)
+	return t
+}
+
+type dotToken struct {
+	Segment          string
+	Offset           int
+	Field            string
+	Bracketed        bool
+	IsArray          bool
+	ArrayIndex       int
+	IsAppendingArray bool
+	IsWildcard       bool
+	IsDescendant     bool
+	Filter           filter
+	IsRange          bool
+	RangeStart       int
+	RangeEnd         int
+	HasRangeEnd      bool
+	// Key is the map key a token of a wildcard match was made for, used instead of
+	// parsing Field, since not every key can be formatted to a string and parsed back.
+	Key reflect.Value
+}
+
+func parsePath(name string) (tokens []*dotToken, err error) {
+	descendant := false
+	for i := 0; i < len(name); {
+		switch name[i] {
+		case '.':
+			if strings.HasPrefix(name[i:], "..") {
+				if descendant || i+2 == len(name) || name[i+2] == '.' {
+					err = parseError(name, name[i:], i, "missing segment after ..")
+					return
+				}
+				descendant = true
+				i += 2
+				continue
+			}
+			i++
+			continue
+		case '[':
+			var t *dotToken
+			t, i, err = parseBracket(name, i)
+			if err != nil {
+				return
+			}
+			if i < len(name) && name[i] != '.' && name[i] != '[' {
+				err = parseError(name, name[t.Offset:], t.Offset, "unexpected character after ]")
+				return
+			}
+			tokens = append(tokens, t)
+		default:
+			end := strings.IndexAny(name[i:], ".[")
+			if end < 0 {
+				end = len(name)
+			} else {
+				end += i
+			}
+			field := name[i:end]
+			if strings.IndexByte(field, ']') >= 0 {
+				err = parseError(name, field, i, "unexpected ]")
+				return
+			}
+
+			t := newDotToken(field)
+			t.Segment = field
+			t.Offset = i
+			tokens = append(tokens, t)
+			i = end
+		}
+
+		if descendant {
+			t := tokens[len(tokens)-1]
+			t.IsDescendant = true
+			t.Segment = ".." + t.Segment
+			t.Offset -= 2
+			descendant = false
+		}
+	}
+	return
+}
+
+// parseBracket parses the segment in brackets that starts at name[start], like [0], [], [key] or ["quoted.key"].
+func parseBracket(name string, start int) (t *dotToken, end int, err error) {
+	i := start + 1
+	if i < len(name) && (name[i] == '"' || name[i] == '\'') {
+		var key string
+		key, i, err = unquoteKey(name, i)
+		if err != nil {
+			return
+		}
+		if i >= len(name) || name[i] != ']' {
+			err = parseError(name, name[start:], start, "missing ] after quoted key")
+			return
+		}
+		// quoted keys are never array indexes
+		t = &dotToken{Field: key}
+	} else if strings.HasPrefix(name[i:], "?(") {
+		n := filterEnd(name, i+2)
+		if n < 0 || n+1 >= len(name) || name[n+1] != ']' {
+			err = parseError(name, name[start:], start, "missing )] after filter")
+			return
+		}
+		t = &dotToken{Field: name[i : n+1]}
+		t.Filter, err = parseFilter(name, name[i+2:n], i+2)
+		if err != nil {
+			return
+		}
+		i = n + 1
+	} else {
+		n := strings.IndexByte(name[i:], ']')
+		if n < 0 {
+			err = parseError(name, name[start:], start, "missing ]")
+			return
+		}
+		var ok bool
+		if t, ok = newRangeToken(name[i : i+n]); !ok {
+			t = newDotToken(name[i : i+n])
+		}
+		if t.Field == "" {
+			t.IsArray = true
+			t.IsAppendingArray = true
+		}
+		i += n
+	}
+
+	end = i + 1
+	t.Segment = name[start:end]
+	t.Offset = start
+	t.Bracketed = true
+	return
+}
+
+// filterEnd returns the index of the ) that closes the filter expression starting at name[start],
+// parentheses and brackets in it are nested and quoted strings are skipped, -1 if it's not closed.
+func filterEnd(name string, start int) int {
+	depth := 0
+	var quote byte
+	for i := start; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == ')':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		}
+	}
+	return -1
+}
+
+// unquoteKey reads the key quoted by name[start], a backslash escapes the character after it.
+func unquoteKey(name string, start int) (key string, end int, err error) {
+	quote := name[start]
+	var b strings.Builder
+	for i := start + 1; i < len(name); i++ {
+		switch name[i] {
+		case '\\':
+			i++
+			if i < len(name) {
+				b.WriteByte(name[i])
+			}
+		case quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(name[i])
+		}
+	}
+
+	err = parseError(name, name[start:], start, "missing closing quote")
+	return
+}
+
+func parseError(path string, segment string, offset int, msg string) error {
+	return &PathError{
+		Op:      "parse",
+		Path:    path,
+		Segment: segment,
+		Offset:  offset,
+		Err:     errors.New(msg),
+	}
+}
+
+func newDotToken(field string) (t *dotToken) {
+	t = &dotToken{Field: field}
+	if field == "*" {
+		t.IsWildcard = true
+		return
+	}
+	if i, err := strconv.Atoi(field); err == nil {
+		t.IsArray = true
+		t.ArrayIndex = i
+	}
+	return
+}
+
+// newRangeToken parses a range like 1:3, :2 or -2:, ok is false if field isn't a range.
+func newRangeToken(field string) (t *dotToken, ok bool) {
+	colon := strings.IndexByte(field, ':')
+	if colon < 0 {
+		return
+	}
+
+	t = &dotToken{Field: field, IsRange: true}
+	var err error
+	if start := field[:colon]; start != "" {
+		if t.RangeStart, err = strconv.Atoi(start); err != nil {
+			return nil, false
+		}
+	}
+	if end := field[colon+1:]; end != "" {
+		if t.RangeEnd, err = strconv.Atoi(end); err != nil {
+			return nil, false
+		}
+		t.HasRangeEnd = true
+	}
+	return t, true
+}
+
+func indexToken(i int) *dotToken {
+	return &dotToken{
+		Segment:    fmt.Sprintf("[%d]", i),
+		Field:      strconv.Itoa(i),
+		Bracketed:  true,
+		IsArray:    true,
+		ArrayIndex: i,
+	}
+}
+
+func fieldToken(name string) *dotToken {
+	return &dotToken{Segment: name, Field: name}
+}
+
+// keyToken returns the token of a map key, keys that can't be written as a dotted segment are quoted.
+func keyToken(key string) *dotToken {
+	t := &dotToken{Segment: key, Field: key}
+	if key == "" || key == "*" || strings.ContainsAny(key, ".[]'\"\\") {
+		t.Segment = `["` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key) + `"]`
+		t.Bracketed = true
+	}
+	return t
+}
+
+// formatPath joins the segments of tokens back into a path.
+func formatPath(tokens []*dotToken) string {
+	var b strings.Builder
+	for i, t := range tokens {
+		if i > 0 && !t.Bracketed && !t.IsDescendant {
+			b.WriteByte('.')
+		}
+		b.WriteString(t.Segment)
+	}
+	return b.String()
+}
+
+// hasWildcard reports whether the path could match more than one value.
+func (p *Path) hasWildcard() bool {
+	for _, t := range p.tokens {
+		if t.isMulti() {
+			return true
+		}
+	}
+	return false
+}
+
+// isMulti reports whether the token could match more than one child.
+func (t *dotToken) isMulti() bool {
+	return t.IsWildcard || t.IsDescendant || t.Filter != nil || t.IsRange
+}
+
+// isSubslice reports whether the only segment that could match many values is a range at the end of the path,
+// which Get and Delete use as one subslice.
+func (p *Path) isSubslice() bool {
+	n := len(p.tokens)
+	if n == 0 || !p.tokens[n-1].IsRange {
+		return false
+	}
+	for _, t := range p.tokens[:n-1] {
+		if t.isMulti() {
+			return false
+		}
+	}
+	return true
+}
+
+// hasFilter reports whether the path has a filter segment, Get and Set act on every match of it.
+func (p *Path) hasFilter() bool {
+	for _, t := range p.tokens {
+		if t.Filter != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// elemRange returns the bounds of the range of token in n elements, negative bounds count from the end,
+// and bounds out of the elements are clamped like slicing in Python.
+func elemRange(token *dotToken, n int) (start, end int) {
+	clamp := func(i int) int {
+		if i < 0 {
+			i += n
+		}
+		return max(0, min(i, n))
+	}
+
+	start = clamp(token.RangeStart)
+	end = n
+	if token.HasRangeEnd {
+		end = clamp(token.RangeEnd)
+	}
+	end = max(start, end)
+	return
+}
+
+// elemIndex returns the index of token for a slice or array of length n,
+// negative indexes count from the end, like -1 for the last element.
+func elemIndex(token *dotToken, n int) (index int, err error) {
+	index = token.ArrayIndex
+	if index < 0 {
+		index += n
+		if index < 0 {
+			err = ErrIndexOutOfRange
+		}
+	}
+	return
+}
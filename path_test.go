@@ -0,0 +1,152 @@
+package reflectutils_test
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+func TestCompile(t *testing.T) {
+	for _, c := range cases {
+		p, err := Compile(c.Name)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		var v *Person
+		for j := 0; j < 2; j++ {
+			err = p.Set(&v, c.Value)
+			if err != nil {
+				t.Error(err)
+				continue
+			}
+
+			val := c.Getter(v)
+			if c.Value != val {
+				t.Errorf("expected is %v, but was %v", c.Value, val)
+			}
+
+			if p.Type(v) == nil {
+				t.Errorf("type of %s should not be nil", p)
+			}
+
+			if strings.Contains(c.Name, "[]") {
+				continue
+			}
+			got, err := p.Get(v)
+			if err != nil {
+				t.Error(err)
+			}
+			if got == nil {
+				t.Errorf("get %s should not be nil", p)
+			}
+		}
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	var errorPaths = []string{
+		"Departments[0",
+		"Departments[0]Name",
+		"Departments]0",
+	}
+
+	for _, name := range errorPaths {
+		if _, err := Compile(name); err == nil {
+			t.Errorf("compile %s should return error", name)
+		}
+
+		var p *Person
+		if err := Set(&p, name, "1"); err == nil {
+			t.Errorf("set %s should return error", name)
+		}
+		if p != nil {
+			t.Errorf("set %s should not change the object, but was %+v", name, p)
+		}
+	}
+}
+
+func TestPathDelete(t *testing.T) {
+	p := &Person{
+		Phones: map[string]string{
+			"felix": "123",
+			"john":  "456",
+		},
+	}
+
+	path := MustCompile("Phones[felix]")
+	if err := path.Delete(p); err != nil {
+		t.Error(err)
+	}
+	if _, ok := p.Phones["felix"]; ok {
+		t.Errorf("felix should be deleted, but was %+v", p.Phones)
+	}
+	if p.Phones["john"] != "456" {
+		t.Errorf("john should not be deleted, but was %+v", p.Phones)
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	var p *Person
+	for i := 0; i < b.N; i++ {
+		Set(&p, "Company.Phone.Number", "911")
+	}
+}
+
+func BenchmarkCompiledSet(b *testing.B) {
+	var p *Person
+	path := MustCompile("Company.Phone.Number")
+	for i := 0; i < b.N; i++ {
+		path.Set(&p, "911")
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	p := &Person{Company: &Company{Phone: &Phone{Number: "911"}}}
+	for i := 0; i < b.N; i++ {
+		Get(p, "Company.Phone.Number")
+	}
+}
+
+func TestPathError(t *testing.T) {
+	p := &Person{Projects: []*Project{{Members: []*Person{{Name: "Felix"}}}}}
+	err := Set(&p, "Projects[0].Members[2].Nmae", "Felix")
+
+	var pe *PathError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected path error, but was %#+v", err)
+	}
+	if !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field, but was %v", err)
+	}
+	if pe.Op != "set" || pe.Path != "Projects[0].Members[2].Nmae" || pe.Segment != "Nmae" || pe.Offset != 23 {
+		t.Errorf("unexpected path error %#+v", pe)
+	}
+	if pe.Type != reflect.TypeOf(Person{}) {
+		t.Errorf("expected type Person, but was %v", pe.Type)
+	}
+	expected := `set Projects[0].Members[2].Nmae: no such field "Nmae" on reflectutils_test.Person`
+	if err.Error() != expected {
+		t.Errorf("expected %s, but was %s", expected, err)
+	}
+
+	_, err = Get(p, "Projects[0].Members[abc]")
+	if !errors.As(err, &pe) || pe.Op != "get" || pe.Segment != "[abc]" || pe.Offset != 19 {
+		t.Errorf("unexpected path error %#+v", err)
+	}
+
+	err = Set(&p, "Score", "abc")
+	if !errors.As(err, &pe) || pe.Segment != "Score" || !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("unexpected path error %#+v", err)
+	}
+
+	_, err = Compile("Projects[0].Members[2")
+	if !errors.As(err, &pe) || pe.Op != "parse" || pe.Offset != 19 {
+		t.Errorf("unexpected path error %#+v", err)
+	}
+}
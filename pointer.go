@@ -0,0 +1,150 @@
+package reflectutils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParsePointer parses a RFC 6901 JSON Pointer like `/projects/0/members/2/name` into a Path,
+// `~1` is unescaped to `/` and `~0` to `~`, and `-` appends to a slice. Fields are resolved by json tags,
+// so that pointers of documents from encoding/json match Go fields, opts can change the tag name.
+func ParsePointer(pointer string, opts ...Option) (p *Path, err error) {
+	p = &Path{path: pointer, pointer: true}
+	p.opts.tagName = "json"
+	for _, opt := range opts {
+		opt(&p.opts)
+	}
+
+	if pointer == "" {
+		return
+	}
+	if pointer[0] != '/' {
+		return nil, parseError(pointer, pointer, 0, "JSON Pointer must start with /")
+	}
+
+	offset := 0
+	for _, segment := range strings.Split(pointer[1:], "/") {
+		offset++
+		var field string
+		field, err = unescapePointer(segment)
+		if err != nil {
+			return nil, parseError(pointer, segment, offset, err.Error())
+		}
+
+		t := &dotToken{Segment: segment, Offset: offset, Field: field}
+		if field == "-" {
+			t.IsArray = true
+			t.IsAppendingArray = true
+		} else if isPointerIndex(field) {
+			t.IsArray = true
+			t.ArrayIndex, _ = strconv.Atoi(field)
+		}
+		p.tokens = append(p.tokens, t)
+		offset += len(segment)
+	}
+	return
+}
+
+// MustParsePointer is like ParsePointer but panics if the pointer can not be parsed.
+func MustParsePointer(pointer string, opts ...Option) *Path {
+	p, err := ParsePointer(pointer, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// GetPointer gets value by a JSON Pointer, see ParsePointer.
+func GetPointer(i interface{}, pointer string, opts ...Option) (value interface{}, err error) {
+	var p *Path
+	p, err = ParsePointer(pointer, opts...)
+	if err != nil {
+		return
+	}
+
+	return p.Get(i)
+}
+
+// SetPointer sets value by a JSON Pointer, see ParsePointer.
+func SetPointer(i interface{}, pointer string, value interface{}, opts ...Option) (err error) {
+	var p *Path
+	p, err = ParsePointer(pointer, opts...)
+	if err != nil {
+		return
+	}
+
+	return p.Set(i, value)
+}
+
+// DeletePointer removes a map key or a slice element, or sets a field to zero value by a JSON Pointer.
+func DeletePointer(i interface{}, pointer string, opts ...Option) (err error) {
+	var p *Path
+	p, err = ParsePointer(pointer, opts...)
+	if err != nil {
+		return
+	}
+
+	return p.Delete(i)
+}
+
+// Pointer formats the path as a RFC 6901 JSON Pointer, segments are written as they are in the path,
+// so the path should be compiled with json tag names for a pointer of the JSON document.
+// Paths with wildcards, recursive descent, filters, ranges or negative indexes have no pointer.
+func (p *Path) Pointer() (pointer string, err error) {
+	var b strings.Builder
+	for _, t := range p.tokens {
+		if t.isMulti() {
+			return "", p.pathError("pointer", p.wrapError(t, nil, errMultiPointer))
+		}
+		if t.IsArray && !t.IsAppendingArray && t.ArrayIndex < 0 {
+			return "", p.pathError("pointer", p.wrapError(t, nil, ErrIndexOutOfRange))
+		}
+
+		b.WriteByte('/')
+		if t.IsAppendingArray {
+			b.WriteByte('-')
+			continue
+		}
+		b.WriteString(pointerEscaper.Replace(t.Field))
+	}
+	return b.String(), nil
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func unescapePointer(segment string) (field string, err error) {
+	if strings.IndexByte(segment, '~') < 0 {
+		return segment, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		if segment[i] != '~' {
+			b.WriteByte(segment[i])
+			continue
+		}
+		i++
+		switch {
+		case i < len(segment) && segment[i] == '0':
+			b.WriteByte('~')
+		case i < len(segment) && segment[i] == '1':
+			b.WriteByte('/')
+		default:
+			return "", errInvalidEscape
+		}
+	}
+	return b.String(), nil
+}
+
+// isPointerIndex reports whether a segment of a JSON Pointer is an array index, which has no leading zeros.
+func isPointerIndex(s string) bool {
+	if s == "" || (s[0] == '0' && len(s) > 1) {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
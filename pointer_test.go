@@ -0,0 +1,128 @@
+package reflectutils_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+type Member struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+}
+
+type Team struct {
+	Name    string            `json:"name"`
+	Members []*Member         `json:"members"`
+	Labels  map[string]string `json:"labels"`
+}
+
+func TestPointer(t *testing.T) {
+	team := &Team{
+		Name:    "Core",
+		Members: []*Member{{Name: "Felix"}, {Name: "Bin"}},
+		Labels:  map[string]string{"a/b": "1", "m~n": "2", "": "3"},
+	}
+
+	var getCases = []struct {
+		pointer  string
+		expected interface{}
+	}{
+		{pointer: "/name", expected: "Core"},
+		{pointer: "/members/1/name", expected: "Bin"},
+		{pointer: "/labels/a~1b", expected: "1"},
+		{pointer: "/labels/m~0n", expected: "2"},
+		{pointer: "/labels/", expected: "3"},
+	}
+	for _, c := range getCases {
+		t.Run(c.pointer, func(t *testing.T) {
+			v, err := GetPointer(team, c.pointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v != c.expected {
+				t.Errorf("expected %v, but was %v", c.expected, v)
+			}
+		})
+	}
+
+	if v, err := GetPointer(team, ""); err != nil || v != team {
+		t.Errorf("expected the whole document, but was %v, %v", v, err)
+	}
+
+	if err := SetPointer(team, "/members/0/email", "felix@example.com"); err != nil || team.Members[0].Email != "felix@example.com" {
+		t.Errorf("expected email set, but was %v, %+v", err, team.Members[0])
+	}
+	if err := SetPointer(team, "/members/-/name", "Anna"); err != nil || len(team.Members) != 3 || team.Members[2].Name != "Anna" {
+		t.Errorf("expected member appended, but was %v, %+v", err, team.Members)
+	}
+
+	if err := DeletePointer(team, "/members/0"); err != nil || len(team.Members) != 2 || team.Members[0].Name != "Bin" {
+		t.Errorf("expected member removed, but was %v, %+v", err, team.Members)
+	}
+	if err := DeletePointer(team, "/labels/a~1b"); err != nil || len(team.Labels) != 2 {
+		t.Errorf("expected label removed, but was %v, %+v", err, team.Labels)
+	}
+	if err := DeletePointer(team, "/name"); err != nil || team.Name != "" {
+		t.Errorf("expected name set to zero, but was %v, %+v", err, team.Name)
+	}
+
+	if _, err := GetPointer(team, "/Members/0", WithStrictMatching()); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field for Go field name, but was %v", err)
+	}
+	if v, err := GetPointer(team, "/Members/0/Name", WithTagName("")); err != nil || v != "Bin" {
+		t.Errorf("expected Go field names without tag name, but was %v, %v", v, err)
+	}
+	if _, err := GetPointer(team, "/members/01"); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected leading zero is not an index, but was %v", err)
+	}
+
+	for _, pointer := range []string{"members", "/labels/a~2"} {
+		if _, err := ParsePointer(pointer); err == nil {
+			t.Errorf("expected parse error for %s", pointer)
+		}
+	}
+}
+
+func TestPathPointer(t *testing.T) {
+	var pointerCases = []struct {
+		path     string
+		expected string
+	}{
+		{path: "members[0].name", expected: "/members/0/name"},
+		{path: `labels["a/b"]`, expected: "/labels/a~1b"},
+		{path: "labels.m~n", expected: "/labels/m~0n"},
+		{path: "members[]", expected: "/members/-"},
+		{path: "", expected: ""},
+	}
+	for _, c := range pointerCases {
+		actual, err := MustCompile(c.path).Pointer()
+		if err != nil || actual != c.expected {
+			t.Errorf("expected %s, but was %s, %v", c.expected, actual, err)
+		}
+	}
+
+	for _, pointer := range []string{"/members/0/name", "/labels/a~1b", "/members/-", "/labels/", ""} {
+		actual, err := MustParsePointer(pointer).Pointer()
+		if err != nil || actual != pointer {
+			t.Errorf("expected %s, but was %s, %v", pointer, actual, err)
+		}
+	}
+
+	var errorCases = []struct {
+		path     string
+		expected string
+	}{
+		{path: "members[*].name", expected: `pointer members[*].name: JSON Pointer can't have segments that match many values`},
+		{path: "..name", expected: `pointer ..name: JSON Pointer can't have segments that match many values`},
+		{path: "members[?(@.name==Bin)]", expected: `pointer members[?(@.name==Bin)]: JSON Pointer can't have segments that match many values`},
+		{path: "members[0:2]", expected: `pointer members[0:2]: JSON Pointer can't have segments that match many values`},
+		{path: "members[-1].name", expected: `pointer members[-1].name: index out of range "[-1]"`},
+	}
+	for _, c := range errorCases {
+		if _, err := MustCompile(c.path).Pointer(); err == nil || err.Error() != c.expected {
+			t.Errorf("expected error %s, but was %v", c.expected, err)
+		}
+	}
+}
@@ -9,7 +9,15 @@ reflectutils is for setting your struct value by using string name and path that
 - `.Person.Name` to set the name of the current property
 - `.Person.Addresses[0].Phone` to set an element of an array property
 - `.Person.Addresses[].Name` it will create a object of address and set it's property
+- `.Person.Addresses[-1].Phone` negative index counts from the end, `-1` is the last element
+- `.Person.Addresses[1:3]` range gets or deletes a subslice, `[:2]` and `[2:]` leave out a bound, SetAll sets every element in it
 - `.Person.MapData.Name` it can also set value to map
+- `.Person.Extra.Theme.Color` nil `interface{}` values are set to a `map[string]interface{}`, or a `[]interface{}` for a bracketed index like `[0]`, and Get and GetType go through values in interfaces
+- `.Person.MapData["example.com"]` to use a map key that has dots or brackets, quoted by `"` or `'`, and `\` escapes the quote
+- `.Person.Addresses[*].Phone` or `.Person.MapData.*.Name` wildcards match every element, key or field, for GetAll and SetAll
+- `..Phone.Number` recursive descent finds `Phone.Number` at any depth, for GetAll, SetAll and DeleteAll
+- `/person/addresses/0/phone` RFC 6901 JSON Pointer for ParsePointer, GetPointer, SetPointer and DeletePointer, fields are resolved by json tags
+- `.Person.Addresses[?(@.City=="Hangzhou" && @.Zip>=310000)].Phone` filter compares fields of every element or map value with `==, !=, <, >, <=, >=, &&, ||`, Get, Set and Delete act on every match
 
 ## How to install
 
@@ -22,88 +30,73 @@ import (
 	"fmt"
 	"log"
 	"reflect"
-	"strconv"
-	"strings"
 )
 
 var NoSuchFieldError = errors.New("no such field")
 
 // Set value of a struct by path using reflect.
-func Set(i interface{}, name string, value interface{}) (err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			err = errors.New(fmt.Sprint(r))
-		}
-	}()
+func Set(i interface{}, name string, value interface{}, opts ...Option) (err error) {
+	var p *Path
+	p, err = Compile(name, opts...)
+	if err != nil {
+		return
+	}
+
+	return p.Set(i, value)
+}
 
+func (p *Path) set(i interface{}, tokens []*dotToken, value interface{}) (err error) {
 	v := reflect.ValueOf(i)
 
-	if v.Kind() != reflect.Ptr {
-		err = errors.New("set object must be a pointer.")
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		err = fmt.Errorf("%w: set object must be a non-nil pointer", ErrNotAddressable)
 		return
 	}
 
-	for v.Elem().Kind() == reflect.Ptr {
-		v = v.Elem()
-		if v.IsNil() {
-			v.Set(reflect.New(v.Type().Elem()))
-		}
-	}
-
-	sv := v.Elem()
+	return p.setValue(v.Elem(), tokens, value)
+}
 
-	if name == "" {
+// setValue sets value to the path of tokens under v, v must be settable.
+func (p *Path) setValue(v reflect.Value, tokens []*dotToken, value interface{}) (err error) {
+	if !v.CanSet() {
+		return ErrNotAddressable
+	}
 
-		switch inputv := value.(type) {
-		case string:
-			err = setStringValue(sv, inputv)
-			if err != nil {
-				return
-			}
-		case []byte:
-			err = setStringValue(sv, string(inputv))
-			if err != nil {
-				return
-			}
-		default:
-			if value == nil {
-				vm := reflect.ValueOf(i)
-				vm.Elem().Set(reflect.Zero(vm.Elem().Type()))
-				return
-			}
+	if len(tokens) == 0 {
+		return assign(v, value, &p.opts)
+	}
 
-			valv := reflect.ValueOf(value)
-			for valv.Kind() == reflect.Ptr {
-				valv = valv.Elem()
-			}
-			sv.Set(valv)
+	sv := v
+	for sv.Kind() == reflect.Ptr {
+		if sv.IsNil() {
+			sv.Set(reflect.New(sv.Type().Elem()))
 		}
-
-		return
+		sv = sv.Elem()
 	}
 
-	var token *dotToken
-	token, err = nextDot(name)
-	if err != nil {
-		return
-	}
+	token := tokens[0]
+	defer func() {
+		if err != nil {
+			err = p.wrapError(token, sv.Type(), err)
+		}
+	}()
 
-	// printv(sv.Interface(), name, value)
+	// printv(sv.Interface(), token.Field)
 
-	if sv.Kind() == reflect.Map {
-		// map must have string type
+	switch sv.Kind() {
+	case reflect.Map:
 		mv := sv
 
-		if mv.Type().Key() != reflect.TypeOf("") {
-			return fmt.Errorf("map key %s must be string type", name)
+		var keyValue reflect.Value
+		keyValue, err = token.mapKey(mv.Type().Key())
+		if err != nil {
+			return
 		}
 
 		if mv.IsNil() {
 			mv.Set(reflect.MakeMap(mv.Type()))
 		}
 
-		keyValue := reflect.ValueOf(token.Field)
-
 		elemType := mv.Type().Elem()
 		mapElem := reflect.New(elemType).Elem()
 
@@ -112,46 +105,52 @@ func Set(i interface{}, name string, value interface{}) (err error) {
 			mapElem.Set(existElem)
 		}
 
-		err = Set(mapElem.Addr().Interface(), token.Left, value)
+		err = p.setValue(mapElem, tokens[1:], value)
 		if err != nil {
 			return
 		}
 
 		mv.SetMapIndex(keyValue, mapElem)
 		return
-	}
 
-	if sv.Kind() == reflect.Slice {
+	case reflect.Slice:
 		av := sv
 		elemType := av.Type().Elem()
 		var newslice reflect.Value
 
+		if !token.IsArray {
+			err = NoSuchFieldError
+			return
+		}
+
+		var index int
+		index, err = elemIndex(token, av.Len())
+		if err != nil {
+			return
+		}
+
 		if token.IsAppendingArray {
 			newslice = av
 			arrayElem := reflect.New(elemType).Elem()
-			err = Set(arrayElem.Addr().Interface(), token.Left, value)
+			err = p.setValue(arrayElem, tokens[1:], value)
 			if err != nil {
 				return
 			}
 			newslice = reflect.Append(newslice, arrayElem)
 		} else {
-			if av.Len() > token.ArrayIndex {
+			if av.Len() > index {
 				newslice = reflect.MakeSlice(av.Type(), 0, 0)
 
-				arrayElem := av.Index(token.ArrayIndex)
-				if !arrayElem.IsValid() {
-					arrayElem.Set(reflect.New(elemType).Elem())
-				}
-
-				err = Set(arrayElem.Addr().Interface(), token.Left, value)
+				arrayElem := av.Index(index)
+				err = p.setValue(arrayElem, tokens[1:], value)
 				if err != nil {
 					return
 				}
-				for i := 0; i < token.ArrayIndex; i++ {
+				for i := 0; i < index; i++ {
 					newslice = reflect.Append(newslice, av.Index(i))
 				}
 				newslice = reflect.Append(newslice, arrayElem)
-				for i := token.ArrayIndex + 1; i < av.Len(); i++ {
+				for i := index + 1; i < av.Len(); i++ {
 					newslice = reflect.Append(newslice, av.Index(i))
 				}
 			} else {
@@ -160,12 +159,12 @@ func Set(i interface{}, name string, value interface{}) (err error) {
 					newslice = reflect.MakeSlice(newslice.Type(), 0, 0)
 				}
 				arrayElem := reflect.New(elemType).Elem()
-				err = Set(arrayElem.Addr().Interface(), token.Left, value)
+				err = p.setValue(arrayElem, tokens[1:], value)
 				if err != nil {
 					return
 				}
-				if newslice.Len() < token.ArrayIndex {
-					for newslice.Len() < token.ArrayIndex {
+				if newslice.Len() < index {
+					for newslice.Len() < index {
 						newslice = reflect.Append(newslice, reflect.Zero(elemType))
 					}
 				}
@@ -178,60 +177,81 @@ func Set(i interface{}, name string, value interface{}) (err error) {
 		av.Set(newslice)
 
 		return
-	}
-
-	if sv.Kind() == reflect.Struct {
-		fv := sv.FieldByNameFunc(func(fname string) bool {
-			return strings.EqualFold(fname, token.Field)
-		})
 
-		if !fv.IsValid() {
-			// err = errors.New(fmt.Sprintf("%+v has no such field `%s`.", sv.Interface(), token.Field))
+	case reflect.Array:
+		if !token.IsArray {
 			err = NoSuchFieldError
 			return
 		}
 
-		err = Set(fv.Addr().Interface(), token.Left, value)
-		return
-	}
+		if token.IsAppendingArray {
+			err = fmt.Errorf("%w: can not append to array", ErrUnsupportedKind)
+			return
+		}
 
-	return
-}
+		var index int
+		index, err = elemIndex(token, sv.Len())
+		if err != nil {
+			return
+		}
 
-type dotToken struct {
-	Field            string
-	Left             string
-	IsArray          bool
-	ArrayIndex       int
-	IsAppendingArray bool
-}
+		// arrays can't grow like slices
+		if index >= sv.Len() {
+			err = ErrIndexOutOfRange
+			return
+		}
+
+		err = p.setValue(sv.Index(index), tokens[1:], value)
+		return
 
-func nextDot(name string) (t *dotToken, err error) {
-	t = &dotToken{}
-	t.Field = strings.Trim(name, ".[")
+	case reflect.Struct:
+		var index []int
+		index, err = fieldIndex(sv.Type(), token.Field, &p.opts)
+		if err != nil {
+			return
+		}
 
-	if i := strings.IndexAny(t.Field, ".["); i > 0 {
-		t.Field, t.Left = t.Field[:i], t.Field[i+1:]
-	}
+		fv := fieldByIndex(sv, index, true)
+		if !fv.IsValid() || !fv.CanSet() {
+			err = ErrNotAddressable
+			return
+		}
 
-	if t.Field[len(t.Field)-1:] == "]" {
-		t.IsArray = true
-		arrayIndexString := t.Field[0 : len(t.Field)-1]
-		if arrayIndexString == "" {
-			t.IsAppendingArray = true
+		err = p.setValue(fv, tokens[1:], value)
+		return
+
+	case reflect.Interface:
+		// the value in an interface can't be set in place, so it is set to a copy that is put back
+		var elem reflect.Value
+		if !sv.IsNil() {
+			elem = reflect.New(sv.Elem().Type()).Elem()
+			elem.Set(sv.Elem())
+		} else if sv.NumMethod() > 0 {
+			return ErrUnsupportedKind
+		} else if token.Bracketed && token.IsArray {
+			// only [0] and [] make slices, dotted numbers like errors.404 are map keys
+			elem = reflect.New(anySliceType).Elem()
 		} else {
-			var i64 int64
-			i64, err = strconv.ParseInt(arrayIndexString, 10, 64)
-			t.ArrayIndex = int(i64)
-			if err != nil {
-				return
-			}
+			elem = reflect.New(anyMapType).Elem()
 		}
+
+		err = p.setValue(elem, tokens, value)
+		if err != nil {
+			return
+		}
+
+		sv.Set(elem)
+		return
 	}
 
-	return
+	return ErrUnsupportedKind
 }
 
+var (
+	anyMapType   = reflect.TypeOf(map[string]interface{}{})
+	anySliceType = reflect.TypeOf([]interface{}{})
+)
+
 func printv(v interface{}, name interface{}) {
 	log.Println("=====")
 	rv := reflect.ValueOf(v)
@@ -246,62 +266,3 @@ func printv(v interface{}, name interface{}) {
 	)
 	log.Println("=====")
 }
-
-func setStringValue(v reflect.Value, value string) (err error) {
-	s := value
-
-	// if type is []byte
-	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
-		v.SetBytes([]byte(s))
-		return
-	}
-
-	switch v.Kind() {
-	case reflect.String:
-		v.SetString(s)
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		var n int64
-		n, err = strconv.ParseInt(s, 10, 64)
-		if err != nil {
-			return
-		}
-		if v.OverflowInt(n) {
-			err = fmt.Errorf("overflow int64 for %d", n)
-			return
-		}
-		v.SetInt(n)
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
-		var n uint64
-		n, err = strconv.ParseUint(s, 10, 64)
-		if err != nil {
-			return
-		}
-		if v.OverflowUint(n) {
-			err = fmt.Errorf("overflow uint64 for %d", n)
-			return
-		}
-		v.SetUint(n)
-	case reflect.Float32, reflect.Float64:
-		var n float64
-		n, err = strconv.ParseFloat(s, v.Type().Bits())
-		if err != nil {
-			return
-		}
-		if v.OverflowFloat(n) {
-			err = fmt.Errorf("overflow float64 for %f", n)
-			return
-		}
-		v.SetFloat(n)
-	case reflect.Bool:
-		var n bool
-		n, err = strconv.ParseBool(s)
-		if err != nil {
-			return
-		}
-		v.SetBool(n)
-	default:
-		err = fmt.Errorf("value %+v can only been set to primary type but was %+v", value, v)
-	}
-
-	return
-}
@@ -0,0 +1,49 @@
+package reflectutils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// SetAll sets value to every existing element, map value or field that matches a path with wildcards,
+// like `Departments[*].Name` or `Languages.*.Code`, slices are not appended to, and count is the number
+// of values that have been set.
+func SetAll(i interface{}, name string, value interface{}, opts ...Option) (count int, err error) {
+	var p *Path
+	p, err = Compile(name, opts...)
+	if err != nil {
+		return
+	}
+
+	return p.SetAll(i, value)
+}
+
+// SetAll sets value to every match of the compiled path, see SetAll.
+func (p *Path) SetAll(i interface{}, value interface{}) (count int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprint(r))
+		}
+		err = p.pathError("set", err)
+	}()
+
+	var matches [][]*dotToken
+	err = p.walk(reflect.ValueOf(i), p.tokens, nil, func(walked, rest []*dotToken, v reflect.Value) error {
+		matches = append(matches, append(walked, rest...))
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	// values are set after walking, so that maps and slices are not changed while they are iterated
+	for _, tokens := range matches {
+		err = p.set(i, tokens, value)
+		if err != nil {
+			return
+		}
+		count++
+	}
+	return
+}
@@ -0,0 +1,106 @@
+package reflectutils_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/sunfmin/reflectutils"
+)
+
+func TestSetAll(t *testing.T) {
+	p := &Person{
+		Departments: []*Department{{Name: "D1"}, {Name: "D2"}, nil},
+		Projects: []*Project{
+			{Members: []*Person{{Name: "Juice"}, {Name: "Bin"}}},
+			{Members: []*Person{{Name: "Anna"}}},
+		},
+		Languages: map[string]Language{
+			"zh_CN": {Code: "zh_CN", Name: "China"},
+			"en_US": {Code: "en_US", Name: "United States"},
+		},
+	}
+
+	count, err := SetAll(p, "Departments[*].Name", "Archived")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 || len(p.Departments) != 3 {
+		t.Errorf("expected 3 departments set without appending, but was %d, %d", count, len(p.Departments))
+	}
+	for _, d := range p.Departments {
+		if d.Name != "Archived" {
+			t.Errorf("expected Archived, but was %+v", d)
+		}
+	}
+
+	count, err = SetAll(p, "Languages.*.Code", "none")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || p.Languages["zh_CN"].Code != "none" || p.Languages["en_US"].Code != "none" || p.Languages["zh_CN"].Name != "China" {
+		t.Errorf("expected map values updated, but was %d, %+v", count, p.Languages)
+	}
+
+	count, err = SetAll(p, "Projects[*].Members[*].Score", "9.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 || p.Projects[0].Members[1].Score != 9.5 || p.Projects[1].Members[0].Score != 9.5 {
+		t.Errorf("expected nested members updated, but was %d", count)
+	}
+
+	count, err = SetAll(p, "Phones.*", "1")
+	if err != nil || count != 0 || p.Phones != nil {
+		t.Errorf("expected nothing set for nil map, but was %d, %v, %+v", count, err, p.Phones)
+	}
+
+	count, err = SetAll(p, "Departments[*].Id", "abc")
+	if err == nil || count != 0 {
+		t.Errorf("expected parse error, but was %d, %v", count, err)
+	}
+
+	if err = Set(p, "Departments[*].Name", "D"); err == nil {
+		t.Errorf("expected Set with wildcard returns error")
+	}
+	if _, err = SetAll(p, "Departments[*].Nmae", "D"); !errors.Is(err, NoSuchFieldError) {
+		t.Errorf("expected no such field, but was %v", err)
+	}
+}
+
+func TestSetAllMapKeys(t *testing.T) {
+	r := &Registry{
+		ByUUID:    map[UUID]string{{1}: "a", {2}: "a"},
+		Locations: map[Point]string{{X: 1, Y: 2}: "a", {X: 3, Y: 4}: "a"},
+	}
+
+	for _, name := range []string{"ByUUID.*", "Locations[*]"} {
+		count, err := SetAll(r, name, "b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 2 {
+			t.Errorf("%s: expected 2 values set, but was %d", name, count)
+		}
+	}
+	if r.ByUUID[UUID{1}] != "b" || r.ByUUID[UUID{2}] != "b" || r.Locations[Point{X: 1, Y: 2}] != "b" || r.Locations[Point{X: 3, Y: 4}] != "b" {
+		t.Errorf("set map keys that can't be parsed failed, %+v", r)
+	}
+
+	matches, err := GetAll(r, "ByUUID[?(@==b)]")
+	if err != nil || len(matches) != 2 || matches[0].Value != "b" {
+		t.Errorf("expected 2 matches, but was %+v, %v", matches, err)
+	}
+
+	for _, name := range []string{"ByUUID.*", "Locations[*]"} {
+		count, err := DeleteAll(r, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 2 {
+			t.Errorf("%s: expected 2 values deleted, but was %d", name, count)
+		}
+	}
+	if len(r.ByUUID) != 0 || len(r.Locations) != 0 {
+		t.Errorf("delete map keys that can't be parsed failed, %+v", r)
+	}
+}
@@ -361,3 +361,56 @@ func TestSetNilToMultiLevelPointer(t *testing.T) {
 		}
 	}
 }
+
+func TestLookup(t *testing.T) {
+	p := &Person{
+		Name:        "",
+		Departments: []*Department{nil, {Name: "D1"}},
+		Phones:      map[string]string{"Home": ""},
+		Languages:   map[string]Language{},
+	}
+
+	var lookupCases = []struct {
+		name          string
+		expectedValue interface{}
+		expectedFound bool
+	}{
+		{name: "Name", expectedValue: "", expectedFound: true},
+		{name: "Company", expectedValue: nil, expectedFound: true},
+		{name: "Company.Name", expectedValue: nil, expectedFound: false},
+		{name: "Departments[0]", expectedValue: nil, expectedFound: true},
+		{name: "Departments[0].Name", expectedValue: nil, expectedFound: false},
+		{name: "Departments[1].Name", expectedValue: "D1", expectedFound: true},
+		{name: "Departments[2].Name", expectedValue: nil, expectedFound: false},
+		{name: "Phones.Home", expectedValue: "", expectedFound: true},
+		{name: "Phones.Company", expectedValue: nil, expectedFound: false},
+		{name: "Languages.en.Code", expectedValue: nil, expectedFound: false},
+		{name: "Projects[0]", expectedValue: nil, expectedFound: false},
+	}
+
+	for _, c := range lookupCases {
+		t.Run(c.name, func(t *testing.T) {
+			v, found, err := Lookup(p, c.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != c.expectedFound || v != c.expectedValue {
+				t.Errorf("expected %#v, %v, but was %#v, %v", c.expectedValue, c.expectedFound, v, found)
+			}
+			if Has(p, c.name) != c.expectedFound {
+				t.Errorf("expected has %v", c.expectedFound)
+			}
+		})
+	}
+
+	if _, found, err := Lookup(p, "NotExists"); found || err == nil {
+		t.Errorf("expected not found with error, but was %v, %v", found, err)
+	}
+	if Has(p, "NotExists") {
+		t.Errorf("expected has false for not exists field")
+	}
+
+	if v := MustGet(p, "Languages.en.Code"); v != "" {
+		t.Errorf("expected Get still returns zero value for missing map key, but was %#v", v)
+	}
+}